@@ -0,0 +1,102 @@
+package nettefatura
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+)
+
+// MaxAttachmentSize faturaya eklenebilecek bir belgenin en büyük boyutu (5MB)
+const MaxAttachmentSize = 5 << 20
+
+// attachmentTypes izin verilen ek belge uzantıları ve içerik tipleri
+var attachmentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+}
+
+// attachmentContentType dosya adı ve içeriğin izin verilen, birbiriyle uyumlu bir tipte
+// olduğunu kontrol edip içerik tipini döner
+func attachmentContentType(filename string, content []byte) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	expected, ok := attachmentTypes[ext]
+	if !ok {
+		return "", fmt.Errorf("desteklenmeyen ek belge tipi: %q (PDF, JPEG veya PNG olmalı)", filename)
+	}
+
+	// Uzantı değiştirilmiş dosyalar içerikten anlaşılır
+	detected := http.DetectContentType(content)
+	if detected != expected {
+		return "", fmt.Errorf("ek belge içeriği uzantısıyla uyuşmuyor: %s (beklenen %s, bulunan %s)", filename, expected, detected)
+	}
+
+	return expected, nil
+}
+
+// AttachDocument mevcut faturaya ek belge (sözleşme, teslim tutanağı vb.) yükler.
+// Belge göndermeden önce yerel olarak kontrol edilir: boş olmamalı, MaxAttachmentSize'ı
+// aşmamalı ve PDF, JPEG veya PNG olmalıdır; uzantı içerikle uyuşmalıdır.
+func (c *Client) AttachDocument(invoiceID, filename string, content []byte) error {
+	if invoiceID == "" {
+		return fmt.Errorf("fatura ID gerekli")
+	}
+	filename = filepath.Base(strings.TrimSpace(filename))
+	if filename == "" || filename == "." {
+		return fmt.Errorf("dosya adı gerekli")
+	}
+	if len(content) == 0 {
+		return fmt.Errorf("ek belge boş olamaz: %s", filename)
+	}
+	if len(content) > MaxAttachmentSize {
+		return fmt.Errorf("ek belge %d bayttan büyük olamaz: %s (%d bayt)", MaxAttachmentSize, filename, len(content))
+	}
+
+	contentType, err := attachmentContentType(filename, content)
+	if err != nil {
+		return err
+	}
+
+	body, err := c.postBodyWithToken("/Invoice/CreateQuick", "/Invoice/UploadAttachment", "ek belge yükleme", func(token string) (io.Reader, string, error) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+
+		fields := [][2]string{
+			{"__RequestVerificationToken", token},
+			{"InvoiceId", invoiceID},
+			{"CompanyId", c.companyID()},
+		}
+		for _, field := range fields {
+			if err := writer.WriteField(field[0], field[1]); err != nil {
+				return nil, "", fmt.Errorf("form oluşturulamadı: %w", err)
+			}
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="File"; filename="%s"`, strings.ReplaceAll(filename, `"`, "")))
+		header.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("form oluşturulamadı: %w", err)
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, "", fmt.Errorf("form oluşturulamadı: %w", err)
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, "", fmt.Errorf("form oluşturulamadı: %w", err)
+		}
+		return &buf, writer.FormDataContentType(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return parseOperationResponse(body, "ek belge yüklenemedi")
+}
@@ -0,0 +1,74 @@
+package nettefatura
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BatchResult toplu fatura oluşturmada tek faturanın sonucu
+type BatchResult struct {
+	Index         int // Girdi listesindeki sıra
+	InvoiceNumber string
+	Err           error
+}
+
+// CreateInvoices faturaları toplu oluşturur. Bir faturanın hatası diğerlerini durdurmaz;
+// sonuçlar girdi sırasıyla döner. Başarısız fatura varsa sonuçlarla birlikte hata da döner.
+// WithQuotaCheck verilmişse kontör yetmediğinde hiçbir fatura gönderilmeden ErrInsufficientQuota döner.
+//
+// Başarısız faturalar tekrar denenmez: client'ın yeniden deneme ayarı yoktur ve yanıtı
+// alınamayan bir fatura portalda kesilmiş olabileceğinden kör tekrar mükerrer fatura
+// oluşturabilir. Tekrar göndermek için Invoice.Reference verilip yalnızca hatalı sonuçlar
+// yeniden CreateInvoices'a verilmelidir; aynı referanslı fatura yeniden kesilmez.
+func (c *Client) CreateInvoices(invoices []Invoice) ([]BatchResult, error) {
+	if c.config.CheckQuota {
+		// Taslaklar kontör harcamaz
+		needed := 0
+		for _, invoice := range invoices {
+			if !invoice.Draft {
+				needed++
+			}
+		}
+		if err := c.checkQuota(needed); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]BatchResult, len(invoices))
+
+	concurrency := c.config.BatchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, invoice := range invoices {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, invoice Invoice) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			invoiceNo, err := c.CreateInvoice(invoice)
+			results[i] = BatchResult{Index: i, InvoiceNumber: invoiceNo, Err: err}
+		}(i, invoice)
+	}
+
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d/%d fatura oluşturulamadı", failed, len(invoices))
+	}
+
+	return results, nil
+}
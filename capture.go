@@ -0,0 +1,57 @@
+package nettefatura
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+)
+
+var (
+	captureCookieRe   = regexp.MustCompile(`(?im)^((?:Set-)?Cookie):.*$`)
+	capturePasswordRe = regexp.MustCompile(`(?i)\b(Password=)[^&\s]*`)
+	captureTokenRe    = regexp.MustCompile(`(__RequestVerificationToken=)[^&\s;]*`)
+	captureTokenTagRe = regexp.MustCompile(`(?is)<input\b[^>]*__RequestVerificationToken[^>]*>`)
+	captureValueRe    = regexp.MustCompile(`(?i)(\bvalue\s*=\s*)("[^"]*"|'[^']*'|[^\s"'>]+)`)
+	// Multipart gövdede (ör. AttachDocument) alan değeri başlıklardan sonraki boş satırı izler
+	captureMultipartRe = regexp.MustCompile(`(?i)(name="(?:__RequestVerificationToken|Password)"\r?\n(?:[^\r\n]+\r?\n)*\r?\n)[^\r\n]*`)
+)
+
+// captureTransport her isteğin ve yanıtın dökümünü Config.Capture'a iletir
+type captureTransport struct {
+	base    http.RoundTripper
+	capture func(reqDump, respDump []byte)
+}
+
+// RoundTrip isteği gönderir, istek ve yanıt dökümünü gizli alanları maskeleyerek iletir.
+// Transport hatasında yanıt dökümü nil'dir.
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqDump, _ := httputil.DumpRequestOut(req, true)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.capture(redactDump(reqDump), nil)
+		return resp, err
+	}
+
+	// DumpResponse gövdeyi okuyup yanıta yeniden yerleştirir
+	respDump, _ := httputil.DumpResponse(resp, true)
+	t.capture(redactDump(reqDump), redactDump(respDump))
+
+	return resp, nil
+}
+
+// redactDump dökümdeki şifre, CSRF token ve cookie değerlerini maskeler
+func redactDump(dump []byte) []byte {
+	if dump == nil {
+		return nil
+	}
+
+	dump = captureCookieRe.ReplaceAll(dump, []byte("$1: ***"))
+	dump = capturePasswordRe.ReplaceAll(dump, []byte("${1}***"))
+	dump = captureTokenRe.ReplaceAll(dump, []byte("${1}***"))
+	dump = captureMultipartRe.ReplaceAll(dump, []byte("${1}***"))
+	dump = captureTokenTagRe.ReplaceAllFunc(dump, func(tag []byte) []byte {
+		return captureValueRe.ReplaceAll(tag, []byte(`${1}"***"`))
+	})
+	return dump
+}
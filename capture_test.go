@@ -0,0 +1,66 @@
+package nettefatura
+
+import (
+	"bytes"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestRedactDump(t *testing.T) {
+	var multipartBody bytes.Buffer
+	writer := multipart.NewWriter(&multipartBody)
+	writer.WriteField("__RequestVerificationToken", "multipart-secret")
+	writer.WriteField("InvoiceId", "5001")
+	writer.Close()
+
+	tests := []struct {
+		name   string
+		dump   string
+		secret string
+		keep   string
+	}{
+		{
+			name:   "form token",
+			dump:   "POST /Invoice/Create HTTP/1.1\r\n\r\n__RequestVerificationToken=form-secret&jsonData=%7B%7D",
+			secret: "form-secret",
+			keep:   "jsonData=%7B%7D",
+		},
+		{
+			name:   "şifre",
+			dump:   "POST /Account/Login HTTP/1.1\r\n\r\nVknTckn=1111111111&Password=pass-secret",
+			secret: "pass-secret",
+			keep:   "VknTckn=1111111111",
+		},
+		{
+			name:   "cookie",
+			dump:   "GET / HTTP/1.1\r\nCookie: .ASPXAUTH=cookie-secret\r\n\r\n",
+			secret: "cookie-secret",
+			keep:   "GET / HTTP/1.1",
+		},
+		{
+			name:   "html token",
+			dump:   "HTTP/1.1 200 OK\r\n\r\n<input name=\"__RequestVerificationToken\" type=\"hidden\" value=\"html-secret\" />",
+			secret: "html-secret",
+			keep:   `type="hidden"`,
+		},
+		{
+			name:   "multipart token",
+			dump:   "POST /Invoice/UploadAttachment HTTP/1.1\r\nContent-Type: " + writer.FormDataContentType() + "\r\n\r\n" + multipartBody.String(),
+			secret: "multipart-secret",
+			keep:   "5001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(redactDump([]byte(tt.dump)))
+			if strings.Contains(got, tt.secret) {
+				t.Errorf("gizli değer maskelenmedi:\n%s", got)
+			}
+			if !strings.Contains(got, tt.keep) {
+				t.Errorf("%q dökümden silindi:\n%s", tt.keep, got)
+			}
+		})
+	}
+}
@@ -1,17 +1,27 @@
 package nettefatura
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/http/cookiejar"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// Portal adresleri. Test ortamı canlı portalla aynı yolları kullanır,
+// ancak kesilen faturalar GİB'e iletilmez ve hukuki geçerliliği yoktur.
+const (
+	ProductionBaseURL = "https://nettefatura.isnet.net.tr"
+	SandboxBaseURL    = "https://nettefaturatest.isnet.net.tr"
+)
+
 // Config client konfigürasyonu
 type Config struct {
 	BaseURL      string
@@ -19,6 +29,56 @@ type Config struct {
 	MeasureUnit  int
 	CurrencyCode string
 	Timeout      time.Duration
+	// Similarity müşteri eşleştirmede kullanılan benzerlik algoritması
+	Similarity SimilarityAlgorithm
+	// MatchWeights müşteri eşleştirme skor ağırlıkları
+	MatchWeights MatchWeights
+	// MatchConfidence mevcut müşteri seçiminde kabul edilen en düşük skor
+	MatchConfidence float64
+	// MatchThreshold mevcut müşteri kabul edilmeden önce en iyi skorun ulaşması gereken alt sınır (0: kapalı)
+	MatchThreshold float64
+	// Tutarların yuvarlanacağı ondalık basamak sayısı ve yöntemi
+	RoundingDecimals int
+	RoundingMode     RoundingMode
+	// AllowedVATRates geçerli KDV oranları (%)
+	AllowedVATRates []int
+	// BatchConcurrency toplu fatura oluşturmada aynı anda gönderilen fatura sayısı
+	BatchConcurrency int
+	// RateLimit saniyedeki en fazla istek sayısı (0 = sınırsız)
+	RateLimit float64
+	// TokenTTL CSRF token'ın önbellekte tutulma süresi (0 = her istekte yenile)
+	TokenTTL time.Duration
+	// Cookies başlangıçta yüklenecek oturum cookie'leri
+	Cookies []*http.Cookie
+	// UserAgent tüm isteklerde gönderilen User-Agent
+	UserAgent string
+	// Headers tüm isteklere eklenen ek header'lar (örn. API gateway kimlik doğrulaması)
+	Headers http.Header
+	// MaxResponseBytes okunacak en büyük yanıt gövdesi (0 = sınırsız)
+	MaxResponseBytes int64
+	// DialTimeout bağlantı kurma süresi sınırı (0 = varsayılan transport)
+	DialTimeout time.Duration
+	// ResponseHeaderTimeout istek gönderildikten sonra yanıt başlıklarının
+	// gelmesi için beklenen en uzun süre (0 = sınırsız)
+	ResponseHeaderTimeout time.Duration
+	// RoundTotalTo ödenecek tutarın yuvarlanacağı adım (örn. 1 = tam lira, 0 = yuvarlama yok)
+	RoundTotalTo float64
+	// AutoResolveLocation CreateCustomer'da boş CityID/DistrictID'yi il/ilçe adlarından bulur
+	AutoResolveLocation bool
+	// CheckQuota CreateInvoices'ta göndermeden önce kalan kontörü kontrol eder
+	CheckQuota bool
+	// Clock fatura ve irsaliye tarihleri için kullanılan saat (nil = time.Now)
+	Clock func() time.Time
+	// Location boş bırakılan fatura tarih/saatlerinin hesaplandığı saat dilimi (nil = time.Local)
+	Location *time.Location
+	// DefaultNotes her faturanın notlarının sonuna eklenen standart notlar
+	DefaultNotes []string
+	// MaxNotes bir faturadaki en fazla not satırı sayısı, varsayılan notlar dahil (0 = sınırsız)
+	MaxNotes int
+	// MaxNoteLength bir not satırının en fazla karakter sayısı (0 = sınırsız)
+	MaxNoteLength int
+	// Capture her isteğin ve yanıtın maskelenmiş dökümünü alan fonksiyon (nil = kapalı)
+	Capture func(reqDump, respDump []byte)
 }
 
 // Option konfigürasyon fonksiyonu
@@ -31,6 +91,14 @@ func WithBaseURL(url string) Option {
 	}
 }
 
+// WithSandbox test ortamını kullanır. Test ortamının kullanıcı bilgileri
+// canlı portaldan ayrıdır.
+func WithSandbox() Option {
+	return func(c *Config) {
+		c.BaseURL = SandboxBaseURL
+	}
+}
+
 // WithCompanyID firma ID'si ayarlar
 func WithCompanyID(id string) Option {
 	return func(c *Config) {
@@ -45,7 +113,7 @@ func WithMeasureUnit(unit int) Option {
 	}
 }
 
-// WithCurrencyCode para birimi ayarlar
+// WithCurrencyCode para birimi ayarlar. Kod ISO 4217 listesinde yoksa NewClient hata döner.
 func WithCurrencyCode(code string) Option {
 	return func(c *Config) {
 		c.CurrencyCode = code
@@ -59,44 +127,322 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithSimilarityAlgorithm müşteri eşleştirmede kullanılacak benzerlik algoritmasını ayarlar
+func WithSimilarityAlgorithm(algorithm SimilarityAlgorithm) Option {
+	return func(c *Config) {
+		c.Similarity = algorithm
+	}
+}
+
+// WithMatchWeights müşteri eşleştirme skor ağırlıklarını ayarlar
+func WithMatchWeights(weights MatchWeights) Option {
+	return func(c *Config) {
+		c.MatchWeights = weights
+	}
+}
+
+// WithMatchConfidence mevcut müşteri seçimi için güven eşiğini ayarlar
+func WithMatchConfidence(threshold float64) Option {
+	return func(c *Config) {
+		c.MatchConfidence = threshold
+	}
+}
+
+// WithMatchThreshold mevcut müşteri seçimi için alt sınırı ayarlar.
+// En iyi aday bu skorun altındaysa CreateCustomerOrGetExisting ErrNoConfidentMatch döner.
+func WithMatchThreshold(threshold float64) Option {
+	return func(c *Config) {
+		c.MatchThreshold = threshold
+	}
+}
+
+// WithRounding satır ve toplam tutarlarının yuvarlama hassasiyetini ve yöntemini ayarlar
+func WithRounding(decimals int, mode RoundingMode) Option {
+	return func(c *Config) {
+		c.RoundingDecimals = decimals
+		c.RoundingMode = mode
+	}
+}
+
+// WithAllowedVATRates geçerli KDV oranlarını ayarlar (varsayılan: 0, 1, 10, 20)
+func WithAllowedVATRates(rates ...int) Option {
+	return func(c *Config) {
+		c.AllowedVATRates = rates
+	}
+}
+
+// WithBatchConcurrency toplu fatura oluşturmada eşzamanlılık sınırını ayarlar (varsayılan: 1)
+func WithBatchConcurrency(n int) Option {
+	return func(c *Config) {
+		c.BatchConcurrency = n
+	}
+}
+
+// WithRateLimit token yenileme dahil tüm istekleri saniyede requestsPerSecond ile sınırlar
+func WithRateLimit(requestsPerSecond float64) Option {
+	return func(c *Config) {
+		c.RateLimit = requestsPerSecond
+	}
+}
+
+// WithTokenTTL CSRF token önbellek süresini ayarlar (varsayılan: 10 dakika)
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.TokenTTL = ttl
+	}
+}
+
+// WithCookies önceden doğrulanmış oturum cookie'lerini yükler, Login gerekmez
+func WithCookies(cookies []*http.Cookie) Option {
+	return func(c *Config) {
+		c.Cookies = cookies
+	}
+}
+
+// WithUserAgent tüm isteklerde gönderilecek User-Agent'ı ayarlar
+func WithUserAgent(userAgent string) Option {
+	return func(c *Config) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithHeader tüm isteklere eklenecek bir header tanımlar; farklı header'lar için tekrar
+// verilebilir. Header'lar istek oluşturulurken eklenir, metotların kendi header'ları
+// (Content-Type, X-Requested-With) ve WithUserAgent bunların üzerine yazar.
+func WithHeader(key, value string) Option {
+	return func(c *Config) {
+		if c.Headers == nil {
+			c.Headers = make(http.Header)
+		}
+		c.Headers.Add(key, value)
+	}
+}
+
+// WithDialTimeout bağlantı kurma süresini sınırlar
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.DialTimeout = timeout
+	}
+}
+
+// WithResponseHeaderTimeout yanıt başlıklarının gelmesi için beklenen süreyi sınırlar
+func WithResponseHeaderTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.ResponseHeaderTimeout = timeout
+	}
+}
+
+// WithRoundTotalTo ödenecek tutarı verilen adıma yuvarlar (örn. 1 ile 99.99 -> 100.00).
+// Fark RoundCounter olarak gönderilir, yuvarlama yöntemi WithRounding'den gelir.
+func WithRoundTotalTo(step float64) Option {
+	return func(c *Config) {
+		c.RoundTotalTo = step
+	}
+}
+
+// WithAutoResolveLocation CreateCustomer'da CityID/DistrictID boşsa
+// CityName/DistrictName'den bulunmasını sağlar
+func WithAutoResolveLocation() Option {
+	return func(c *Config) {
+		c.AutoResolveLocation = true
+	}
+}
+
+// WithQuotaCheck CreateInvoices'ın göndermeden önce kalan kontörü kontrol etmesini,
+// kontör yetmiyorsa hiçbir faturayı göndermeden ErrInsufficientQuota dönmesini sağlar
+func WithQuotaCheck() Option {
+	return func(c *Config) {
+		c.CheckQuota = true
+	}
+}
+
+// WithDefaultNotes her faturanın kendi notlarından sonra eklenecek standart notları ayarlar
+// (örn. yasal açıklamalar). Invoice.SkipDefaultNotes ile fatura bazında kapatılabilir.
+func WithDefaultNotes(notes []string) Option {
+	return func(c *Config) {
+		c.DefaultNotes = append([]string(nil), notes...)
+	}
+}
+
+// WithMaxNotes bir faturada gönderilebilecek en fazla not satırı sayısını ayarlar
+// (varsayılan: 0 = sınırsız). Sınır aşılırsa fatura gönderilmez ve hata döner.
+func WithMaxNotes(n int) Option {
+	return func(c *Config) {
+		c.MaxNotes = n
+	}
+}
+
+// WithMaxNoteLength fatura ve irsaliye notlarında bir satırın en fazla karakter sayısını
+// ayarlar (varsayılan: 0 = sınırsız). Daha uzun not verilirse belge gönderilmez ve hata döner.
+func WithMaxNoteLength(n int) Option {
+	return func(c *Config) {
+		c.MaxNoteLength = n
+	}
+}
+
+// WithCapture her HTTP isteğinin ve yanıtının ham dökümünü (httputil.DumpRequestOut/DumpResponse)
+// fn'e iletir. Şifre, CSRF token ve cookie değerleri maskelenir. Hata ayıklama içindir;
+// yanıt gövdeleri tamamen belleğe okunur.
+func WithCapture(fn func(reqDump, respDump []byte)) Option {
+	return func(c *Config) {
+		c.Capture = fn
+	}
+}
+
+// WithClock boş bırakılan fatura/irsaliye tarihleri ve tarih kontrolleri için
+// kullanılan saati değiştirir. Testlerde tarihi sabitlemek için kullanılır.
+func WithClock(now func() time.Time) Option {
+	return func(c *Config) {
+		c.Clock = now
+	}
+}
+
+// WithLocation boş bırakılan fatura/irsaliye tarihleri ile yalnızca gün verilen
+// faturaların saati için kullanılan saat dilimini ayarlar (örn. Europe/Istanbul)
+func WithLocation(loc *time.Location) Option {
+	return func(c *Config) {
+		c.Location = loc
+	}
+}
+
+// WithMaxResponseBytes okunacak en büyük yanıt gövdesini ayarlar (0 = sınırsız)
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Config) {
+		c.MaxResponseBytes = n
+	}
+}
+
 // Client NetteFatura API client
 type Client struct {
 	httpClient *http.Client
 	config     *Config
-	token      string
+	token      *tokenCache
+	reference  *referenceCache
+	ctx        context.Context
+	// transport bağlantı havuzunu tutan temel transport (Close ile boşaltılır)
+	transport http.RoundTripper
+}
+
+// tokenCache CSRF token önbelleği, WithContext kopyalarıyla paylaşılır
+type tokenCache struct {
+	mu        sync.Mutex // Config.CompanyID de bu kilitle korunur
+	value     string
+	fetchedAt time.Time
 }
 
 // Customer müşteri bilgileri
 type Customer struct {
-	Name         string
-	TaxNumber    string // TC Kimlik No
-	Email        string
-	Phone        string
-	Address      string
-	CityID       string
-	CityName     string
-	DistrictID   string
-	PostalCode   string
-	BuildingNo   string
-	TaxOfficeID  string // Vergi dairesi ID (-1 for default)
-	CustomerType int    // 1=Bireysel, 2=Kurumsal
-	SendingType  int    // 1=Elektronik, 2=Kağıt
+	Name         string       `json:"name"`
+	TaxNumber    string       `json:"tax_number"` // TC Kimlik No
+	Email        string       `json:"email,omitempty"`
+	Phone        string       `json:"phone,omitempty"`
+	Address      string       `json:"address,omitempty"`
+	CityID       string       `json:"city_id,omitempty"`
+	CityName     string       `json:"city_name,omitempty"`
+	DistrictID   string       `json:"district_id,omitempty"`
+	DistrictName string       `json:"district_name,omitempty"`
+	PostalCode   string       `json:"postal_code,omitempty"`
+	BuildingNo   string       `json:"building_no,omitempty"`
+	TaxOfficeID  string       `json:"tax_office_id,omitempty"` // Vergi dairesi ID (-1 for default)
+	CustomerType CustomerType `json:"customer_type,omitempty"` // CustomerTypeIndividual, CustomerTypeCorporate
+	SendingType  SendingType  `json:"sending_type,omitempty"`  // SendingTypeElectronic, SendingTypePaper
 }
 
 // Product ürün bilgileri
 type Product struct {
-	Name     string
-	Quantity float64
-	Price    float64 // KDV hariç birim fiyat
-	VATRate  int     // KDV oranı (%)
+	ProductID string  `json:"product_id,omitempty"` // Katalog ürün ID (opsiyonel, bkz. GetProductList)
+	Name      string  `json:"name"`
+	Quantity  float64 `json:"quantity"`
+	Price     float64 `json:"price"`    // KDV hariç birim fiyat
+	VATRate   int     `json:"vat_rate"` // KDV oranı (%)
+
+	// ExactPrice verilirse Price yerine kullanılan kesin KDV hariç birim fiyat.
+	// Money bir struct olduğundan omitempty etkisizdir; sıfır değer omitzero ile (Go 1.24+) yazılmaz.
+	ExactPrice Money `json:"exact_price,omitzero"`
+
+	// Gümrük sınıflandırması (ihracat faturaları için)
+	GTIPCode           string `json:"gtip_code,omitempty"`           // GTİP numarası
+	ClassificationCode string `json:"classification_code,omitempty"` // Sınıflandırma kodu
+	ClassificationID   int    `json:"classification_id,omitempty"`   // Sınıflandırma kodu ID
+
+	// Menşei (ithal mallar için)
+	CountryOfOriginID int    `json:"country_of_origin_id,omitempty"` // Menşei ID (bkz. GetMenseiID)
+	CountryOfOrigin   string `json:"country_of_origin,omitempty"`    // Menşei ülke adı
 }
 
 // Invoice fatura bilgileri
 type Invoice struct {
+	CustomerID string    `json:"customer_id"`
+	Products   []Product `json:"products"`
+	Date       time.Time `json:"date"`
+	Notes      []string  `json:"notes,omitempty"`
+
+	// InvoiceTime fatura saati ("15:04:05" veya "15:04"). Verilirse Date'in saat bileşeninin
+	// önüne geçer. Verilmezse Date'in saati kullanılır; Date yalnızca gün içeriyorsa
+	// (00:00:00) saat, client saat diliminde (WithLocation) gönderim anının saati olur.
+	InvoiceTime string `json:"invoice_time,omitempty"`
+
+	// Boş bırakılırsa mükellef sorgusuyla belirlenir
+	RecipientType    RecipientType `json:"recipient_type,omitempty"`     // RecipientTypeEInvoice, RecipientTypeEArchive
+	ReceiverInboxTag string        `json:"receiver_inbox_tag,omitempty"` // Alıcı posta kutusu etiketi (e-fatura için, verilirse RecipientType "1" olur)
+
+	// 0 bırakılırsa alıcı kaydındaki gönderim şekli, o da yoksa elektronik kullanılır
+	SendingType SendingType `json:"sending_type,omitempty"` // SendingTypeElectronic, SendingTypePaper
+
+	// Faturaya bağlanacak e-İrsaliyeler
+	DispatchList []DispatchReference `json:"dispatch_list,omitempty"`
+
+	// Reference çağıranın sipariş/işlem numarası. Verilirse CreateInvoice aynı
+	// referanslı bir fatura bulduğunda yenisini kesmeden onun numarasını döner.
+	Reference string `json:"reference,omitempty"`
+
+	// Draft verilirse fatura kesilmez, onay için taslak olarak kaydedilir ve
+	// CreateInvoice fatura numarası yerine taslak ID'sini döner
+	Draft bool `json:"draft,omitempty"`
+
+	// Belge iskontosu: satır tutarları değişmeden KDV dahil toplamdan düşülür ve
+	// TotalDiscountAmount olarak gönderilir. Tutar veya oran verilir, ikisi birden verilemez.
+	DiscountAmount float64 `json:"discount_amount,omitempty"` // İskonto tutarı
+	DiscountRate   float64 `json:"discount_rate,omitempty"`   // Toplam üzerinden iskonto oranı (%)
+
+	// ExtraFields fatura JSON'ına eklenecek, kütüphanenin modellemediği alanlar.
+	// Aynı isimli varsayılan alanların üzerine yazar; portal yeni bir alan istediğinde geçici çözümdür.
+	ExtraFields map[string]interface{} `json:"extra_fields,omitempty"`
+
+	// Döviz: CurrencyCode boşsa client'ın para birimi (WithCurrencyCode) kullanılır.
+	// TRY dışındaki para birimlerinde CrossRate (1 birim döviz = CrossRate TL) zorunludur.
+	// Satır fiyatları fatura para birimindedir; PricesInTRY verilirse TL kabul edilip
+	// CrossRate ile fatura para birimine çevrilir.
+	CurrencyCode string  `json:"currency_code,omitempty"`
+	CrossRate    float64 `json:"cross_rate,omitempty"`
+	PricesInTRY  bool    `json:"prices_in_try,omitempty"`
+
+	// SkipDefaultNotes verilirse WithDefaultNotes ile ayarlanan notlar bu faturaya eklenmez
+	SkipDefaultNotes bool `json:"skip_default_notes,omitempty"`
+}
+
+// CustomerResult müşteri oluşturma yanıtı
+type CustomerResult struct {
+	CustomerID     string                 `json:"-"`
+	IdAlici        int                    `json:"IdAlici"`
+	AliciAdi       string                 `json:"AliciAdi"`
+	MusteriNo      string                 `json:"musteriNo"`
+	IsEInvoiceUser bool                   `json:"EFaturaKullanicisi"` // Alıcı e-fatura mükellefi mi
+	Warnings       []string               `json:"-"`
+	Raw            map[string]interface{} `json:"-"` // Ham yanıt
+}
+
+// InvoiceResult oluşturulan fatura bilgileri
+type InvoiceResult struct {
+	InvoiceNumber string
+	ETTN          string
+}
+
+// CustomerInvoiceResult müşteri ve fatura birlikte oluşturma sonucu
+type CustomerInvoiceResult struct {
 	CustomerID string
-	Products   []Product
-	Date       time.Time
-	Notes      []string
+	WasCreated bool // Müşteri yeni oluşturulduysa true, mevcut müşteri eşleştiyse false
+	Invoice    *InvoiceResult
 }
 
 // RecipientListItem müşteri listesi öğesi
@@ -129,6 +475,35 @@ type RecipientListItem struct {
 	MaskingRecipientName string `json:"MaskingRecipientName"`
 }
 
+// ToCustomer liste kaydını CreateCustomer ve fatura akışlarında kullanılan Customer'a çevirir.
+// Sıfır olan ID'ler boş bırakılır.
+func (r RecipientListItem) ToCustomer() Customer {
+	return Customer{
+		Name:         r.AliciAdi,
+		TaxNumber:    r.Vnktckn,
+		Email:        r.Email,
+		Phone:        r.Telefon,
+		Address:      r.SokakAdi,
+		CityID:       idString(r.IdIl),
+		CityName:     r.IlAdi,
+		DistrictID:   idString(r.IdIlce),
+		DistrictName: r.IlceAdi,
+		PostalCode:   r.PostaKodu,
+		BuildingNo:   r.BinaNo,
+		TaxOfficeID:  idString(r.IdVergiDairesi),
+		CustomerType: CustomerType(r.AliciTipi),
+		SendingType:  SendingType(r.FaturaGonderimSekli),
+	}
+}
+
+// idString pozitif ID'yi string'e çevirir, diğerlerinde boş döner
+func idString(id int) string {
+	if id <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", id)
+}
+
 // RecipientListResponse müşteri listesi API yanıtı
 type RecipientListResponse struct {
 	Draw            int                 `json:"draw"`
@@ -137,19 +512,35 @@ type RecipientListResponse struct {
 	Data            []RecipientListItem `json:"data"`
 }
 
-// CalculatePriceWithoutVAT KDV dahil fiyattan KDV hariç fiyat hesaplar
+// CalculatePriceWithoutVAT KDV dahil fiyattan KDV hariç fiyat hesaplar.
+// Hesap ondalık aritmetikle yapılır (120, %20 -> 100); sonuç yuvarlanmaz.
 func CalculatePriceWithoutVAT(priceWithVAT float64, vatRate int) float64 {
-	return priceWithVAT / (1 + float64(vatRate)/100)
+	return CalculatePriceWithoutVATExact(NewMoney(priceWithVAT), vatRate).Float64()
 }
 
 // CalculatePriceWithVAT KDV hariç fiyattan KDV dahil fiyat hesaplar
 func CalculatePriceWithVAT(priceWithoutVAT float64, vatRate int) float64 {
-	return priceWithoutVAT * (1 + float64(vatRate)/100)
+	return CalculatePriceWithVATExact(NewMoney(priceWithoutVAT), vatRate).Float64()
 }
 
 // CalculateVATAmount KDV tutarını hesaplar
 func CalculateVATAmount(priceWithoutVAT float64, vatRate int) float64 {
-	return priceWithoutVAT * float64(vatRate) / 100
+	return CalculateVATAmountExact(NewMoney(priceWithoutVAT), vatRate).Float64()
+}
+
+// CalculatePriceWithoutVATRounded KDV hariç fiyatı 2 ondalık basamağa yuvarlanmış hesaplar
+func CalculatePriceWithoutVATRounded(priceWithVAT float64, vatRate int) float64 {
+	return CalculatePriceWithoutVATExact(NewMoney(priceWithVAT), vatRate).Round(2, RoundHalfUp).Float64()
+}
+
+// CalculatePriceWithVATRounded KDV dahil fiyatı 2 ondalık basamağa yuvarlanmış hesaplar
+func CalculatePriceWithVATRounded(priceWithoutVAT float64, vatRate int) float64 {
+	return CalculatePriceWithVATExact(NewMoney(priceWithoutVAT), vatRate).Round(2, RoundHalfUp).Float64()
+}
+
+// CalculateVATAmountRounded KDV tutarını 2 ondalık basamağa yuvarlanmış hesaplar
+func CalculateVATAmountRounded(priceWithoutVAT float64, vatRate int) float64 {
+	return CalculateVATAmountExact(NewMoney(priceWithoutVAT), vatRate).Round(2, RoundHalfUp).Float64()
 }
 
 // NewClient yeni bir NetteFatura client oluşturur
@@ -160,11 +551,21 @@ func NewClient(companyID string, options ...Option) (*Client, error) {
 
 	// Default config
 	config := &Config{
-		BaseURL:      "https://nettefatura.isnet.net.tr",
-		CompanyID:    companyID,
-		MeasureUnit:  67, // Adet
-		CurrencyCode: "TRY",
-		Timeout:      30 * time.Second,
+		BaseURL:          ProductionBaseURL,
+		CompanyID:        companyID,
+		MeasureUnit:      67, // Adet
+		CurrencyCode:     "TRY",
+		Timeout:          30 * time.Second,
+		Similarity:       SimilarityLevenshtein,
+		MatchWeights:     DefaultMatchWeights(),
+		MatchConfidence:  0.5,
+		RoundingDecimals: 2,
+		RoundingMode:     RoundHalfUp,
+		AllowedVATRates:  DefaultVATRates(),
+		BatchConcurrency: 1,
+		TokenTTL:         10 * time.Minute,
+		UserAgent:        UserAgent(),
+		MaxResponseBytes: 10 << 20, // 10MB
 	}
 
 	// Apply options
@@ -172,18 +573,94 @@ func NewClient(companyID string, options ...Option) (*Client, error) {
 		opt(config)
 	}
 
-	jar, err := cookiejar.New(nil)
+	if !IsValidCurrencyCode(config.CurrencyCode) {
+		return nil, fmt.Errorf("geçersiz para birimi: %q (ISO 4217 kodu olmalı)", config.CurrencyCode)
+	}
+
+	jar, err := newSessionJar()
 	if err != nil {
-		return nil, fmt.Errorf("cookie jar oluşturulamadı: %w", err)
+		return nil, err
 	}
 
-	return &Client{
-		httpClient: &http.Client{
-			Jar:     jar,
-			Timeout: config.Timeout,
-		},
-		config: config,
-	}, nil
+	base := newBaseTransport(config)
+	inner := base
+
+	// Döküm header'lar eklendikten sonra, gönderilen haliyle alınır
+	if config.Capture != nil {
+		inner = &captureTransport{base: base, capture: config.Capture}
+	}
+
+	// User-Agent tüm giden isteklere uygulanır
+	var transport http.RoundTripper = &headerTransport{
+		base:      inner,
+		userAgent: config.UserAgent,
+	}
+
+	// Hız sınırı tüm giden isteklere uygulanır
+	if config.RateLimit > 0 {
+		transport = &rateLimitTransport{
+			base:    transport,
+			limiter: newRateLimiter(config.RateLimit),
+		}
+	}
+
+	httpClient := &http.Client{
+		Jar:       jar,
+		Timeout:   config.Timeout,
+		Transport: transport,
+	}
+
+	client := &Client{
+		httpClient: httpClient,
+		config:     config,
+		token:      &tokenCache{},
+		reference:  &referenceCache{},
+		transport:  base,
+	}
+
+	// Önceden doğrulanmış oturum
+	if len(config.Cookies) > 0 {
+		if err := client.setCookies(config.Cookies); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// WithContext tüm istekleri ctx ile yapan bir client kopyası döner.
+// Kopya oturumu, token önbelleğini ve konfigürasyonu asıl client ile paylaşır;
+// ctx'in iptali veya süresinin dolması yalnızca kopyanın isteklerini sonlandırır.
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+//	defer cancel()
+//	list, err := client.WithContext(ctx).GetRecipientList(0, 200)
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nettefatura: nil context")
+	}
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+// Config client'ın geçerli konfigürasyonunun kopyasını döner.
+// Kopya üzerinde yapılan değişiklikler client'ı etkilemez. Oluşturulduktan sonra
+// değişebilen tek alan CompanyID olduğundan kopya SetCompanyID ile aynı kilitle alınır.
+func (c *Client) Config() Config {
+	c.token.mu.Lock()
+	config := *c.config
+	c.token.mu.Unlock()
+
+	config.AllowedVATRates = append([]int(nil), c.config.AllowedVATRates...)
+	config.DefaultNotes = append([]string(nil), c.config.DefaultNotes...)
+	config.Headers = c.config.Headers.Clone()
+	config.Cookies = nil
+	for _, cookie := range c.config.Cookies {
+		copied := *cookie
+		config.Cookies = append(config.Cookies, &copied)
+	}
+	return config
 }
 
 // Login sisteme giriş yapar
@@ -198,426 +675,1267 @@ func (c *Client) Login(vknTckn, password string) error {
 		"VknTckn":                    {vknTckn},
 		"Password":                   {password},
 		"RememberMe":                 {"on"},
-		"__RequestVerificationToken": {c.token},
+		"__RequestVerificationToken": {c.currentToken()},
 	}
 
-	resp, err := c.httpClient.PostForm(c.config.BaseURL+"/Account/Login", form)
+	req, err := c.newRequest("POST", c.config.BaseURL+"/Account/Login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// Başarılı girişte portal ana sayfaya yönlendirir, yönlendirme takip edilmeden kontrol edilir
+	noRedirect := *c.httpClient
+	noRedirect.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := noRedirect.Do(req)
 	if err != nil {
 		return fmt.Errorf("login isteği başarısız: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// 302 redirect veya 200 başarılı
-	if resp.StatusCode != http.StatusFound && resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	// Çok sayıda başarısız girişten sonra portal CAPTCHA sayfası döner (status'tan bağımsız)
+	if id, ok := captchaChallenge(string(body)); ok {
+		return captchaError(id)
+	}
+
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		location := strings.ToLower(resp.Header.Get("Location"))
+		if strings.Contains(location, "captcha") {
+			return ErrCaptchaRequired
+		}
+		// Giriş sayfasına geri yönlendirme başarısız giriştir
+		if strings.Contains(location, "/account/login") {
+			return ErrInvalidCredentials
+		}
+	case resp.StatusCode == http.StatusOK:
+		// Hatalı girişte form hata mesajıyla tekrar 200 döner
+		if isLoginForm(string(body)) {
+			if message := loginErrorMessage(string(body)); message != "" {
+				return fmt.Errorf("%w: %s", ErrInvalidCredentials, message)
+			}
+			return ErrInvalidCredentials
+		}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrInvalidCredentials
+	default:
 		return fmt.Errorf("login başarısız, status: %d, body: %s", resp.StatusCode, string(body))
 	}
 
+	// Giriş öncesi token oturumla geçersizleşir
+	c.invalidateToken()
+
 	return nil
 }
 
+// SetCompanyID aynı oturumla başka bir firma adına işlem yapmak için firma ID'sini değiştirir.
+// Portal firma ID'sini her istekte (CompanyId, IdFirma, CompanyIdFilter) aldığından ayrıca firma değiştirme isteği gerekmez.
+// Eşzamanlı isteklerle birlikte çağrılabilir; o anda hazırlanmakta olan istek eski veya yeni ID ile gidebilir.
+func (c *Client) SetCompanyID(id string) error {
+	if id == "" {
+		return fmt.Errorf("company ID zorunludur")
+	}
+	c.token.mu.Lock()
+	c.config.CompanyID = id
+	c.token.mu.Unlock()
+	return nil
+}
+
+// companyID kullanımdaki firma ID'sini SetCompanyID ile yarışmadan okur
+func (c *Client) companyID() string {
+	c.token.mu.Lock()
+	defer c.token.mu.Unlock()
+	return c.config.CompanyID
+}
+
+// Logout oturumu kapatır, cookie'leri ve önbellekteki token'ı temizler
+func (c *Client) Logout() error {
+	_, logoutErr := c.postWithToken("/Invoice/CreateQuick", "/Account/LogOff", url.Values{}, "çıkış")
+
+	// Sunucu isteği başarısız olsa bile yerel oturum temizlenir. Jar yerinde boşaltıldığından
+	// aynı anda çalışan istekler eski veya yeni cookie'lerle gider ancak yarış oluşmaz.
+	if jar, ok := c.httpClient.Jar.(*sessionJar); ok {
+		if err := jar.reset(); err != nil {
+			return err
+		}
+	}
+	c.invalidateToken()
+
+	return logoutErr
+}
+
+// Close client'ın boşta bekleyen bağlantılarını kapatır. Oturum açık kalır; client
+// kapatıldıktan sonra da kullanılabilir, gerekirse yeni bağlantı açılır. Zaman aşımı
+// ayarlanmamış client'lar paylaşılan http.DefaultTransport'u kullandığından onların
+// bağlantılarına dokunulmaz.
+func (c *Client) Close() error {
+	if transport, ok := c.transport.(*http.Transport); ok && c.transport != http.DefaultTransport {
+		transport.CloseIdleConnections()
+	}
+	return nil
+}
+
+// LogoutAndClose oturumu kapatıp client'ın bağlantılarını serbest bırakır.
+// Çıkış isteği başarısız olsa bile bağlantılar kapatılır ve çıkış hatası döner.
+func (c *Client) LogoutAndClose() error {
+	logoutErr := c.Logout()
+	if err := c.Close(); err != nil {
+		return err
+	}
+	return logoutErr
+}
+
 // CreateCustomer yeni müşteri oluşturur
 func (c *Client) CreateCustomer(customer Customer) (string, error) {
-	// Token güncelle
-	if err := c.updateToken("/Invoice/CreateQuick"); err != nil {
-		return "", fmt.Errorf("token güncellenemedi: %w", err)
+	result, err := c.CreateCustomerResult(customer)
+	if err != nil {
+		return "", err
+	}
+	return result.CustomerID, nil
+}
+
+// resolveLocation boş CityID/DistrictID'yi CityName/DistrictName'den bulur
+func resolveLocation(customer *Customer) error {
+	if customer.CityID == "" && customer.CityName != "" {
+		cityID := GetCityID(customer.CityName)
+		if cityID == "-1" {
+			return fmt.Errorf("il bulunamadı: %s", customer.CityName)
+		}
+		customer.CityID = cityID
+	}
+
+	if customer.DistrictID == "" && customer.DistrictName != "" && customer.CityID != "" {
+		districtID := GetDistrictID(customer.CityID, customer.DistrictName)
+		if districtID == -1 {
+			return fmt.Errorf("ilçe bulunamadı: %s (%s)", customer.DistrictName, customer.CityName)
+		}
+		customer.DistrictID = fmt.Sprintf("%d", districtID)
+	}
+
+	return nil
+}
+
+// Validate müşteri bilgilerini ağ isteği yapmadan kontrol eder: ad, alıcı tipine uygun
+// TCKN/VKN, elektronik gönderimde e-posta, telefon biçimi ve il/ilçenin veri setinde
+// bulunması. CreateCustomer göndermeden önce aynı kontrolleri yapar.
+func (c Customer) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("müşteri adı zorunludur")
+	}
+
+	taxNumber := strings.TrimSpace(c.TaxNumber)
+	switch c.CustomerType {
+	case CustomerTypeAuto:
+		if taxNumber == "" {
+			return fmt.Errorf("TC kimlik no veya vergi kimlik no zorunludur")
+		}
+		if !isValidTCKN(taxNumber) && !isValidVKN(taxNumber) {
+			return fmt.Errorf("geçersiz TC kimlik no / vergi kimlik no: %s", taxNumber)
+		}
+	case CustomerTypeIndividual:
+		if taxNumber == "" {
+			return fmt.Errorf("TC kimlik no zorunludur")
+		}
+		if !isValidTCKN(taxNumber) {
+			return fmt.Errorf("geçersiz TC kimlik no: %s", taxNumber)
+		}
+	case CustomerTypeCorporate: // Şahıs şirketleri TCKN kullanabilir
+		if taxNumber == "" {
+			return fmt.Errorf("vergi kimlik no zorunludur")
+		}
+		if !isValidVKN(taxNumber) && !isValidTCKN(taxNumber) {
+			return fmt.Errorf("geçersiz vergi kimlik no: %s", taxNumber)
+		}
+	default:
+		return fmt.Errorf("geçersiz alıcı tipi: %d (1=Bireysel, 2=Kurumsal)", c.CustomerType)
+	}
+
+	switch c.SendingType {
+	case SendingTypeDefault, SendingTypeElectronic:
+		if strings.TrimSpace(c.Email) == "" {
+			return fmt.Errorf("elektronik gönderim için e-posta zorunludur")
+		}
+	case SendingTypePaper:
+	default:
+		return fmt.Errorf("geçersiz gönderim şekli: %d (1=Elektronik, 2=Kağıt)", c.SendingType)
+	}
+	if strings.TrimSpace(c.Email) != "" {
+		if err := validateEmail(c.Email); err != nil {
+			return err
+		}
+	}
+
+	if c.Phone != "" {
+		if _, err := NormalizePhone(c.Phone); err != nil {
+			return err
+		}
+	}
+
+	if c.CityID == "" {
+		return fmt.Errorf("il zorunludur")
+	}
+	if GetCityName(c.CityID) == "-1" {
+		return fmt.Errorf("bilinmeyen il ID: %s", c.CityID)
+	}
+	if c.DistrictID == "" {
+		return fmt.Errorf("ilçe zorunludur")
+	}
+	districtName := GetDistrictName(c.CityID, parseIntOrZero(c.DistrictID))
+	if districtName == "-1" {
+		return fmt.Errorf("ilçe ID %s, il ID %s içinde bulunamadı", c.DistrictID, c.CityID)
+	}
+
+	// Ad verilmişse ID ile aynı yeri göstermeli
+	if cityName := GetCityName(c.CityID); c.CityName != "" && normalizeString(c.CityName) != normalizeString(cityName) {
+		return fmt.Errorf("il adı ile il ID uyuşmuyor: %q verildi, ID %s %q", c.CityName, c.CityID, cityName)
+	}
+	if c.DistrictName != "" && normalizeString(c.DistrictName) != normalizeString(districtName) &&
+		GetDistrictID(c.CityID, c.DistrictName) != parseIntOrZero(c.DistrictID) {
+		return fmt.Errorf("ilçe adı ile ilçe ID uyuşmuyor: %q verildi, ID %s %q", c.DistrictName, c.DistrictID, districtName)
 	}
 
-	// Validasyonlar
-	if customer.Name == "" {
-		return "", fmt.Errorf("müşteri adı zorunludur")
+	return nil
+}
+
+// CreateCustomerResult yeni müşteri oluşturur ve sunucu yanıtının tamamını döner
+func (c *Client) CreateCustomerResult(customer Customer) (*CustomerResult, error) {
+	// İl/ilçe ID'leri adlardan
+	if c.config.AutoResolveLocation {
+		if err := resolveLocation(&customer); err != nil {
+			return nil, err
+		}
 	}
-	if customer.TaxNumber == "" {
-		return "", fmt.Errorf("TC kimlik no zorunludur")
+
+	// Validasyon
+	if err := customer.Validate(); err != nil {
+		return nil, err
 	}
-	if customer.SendingType == 1 && customer.Email == "" {
-		return "", fmt.Errorf("elektronik gönderim için e-posta zorunludur")
+
+	customer.TaxNumber = strings.TrimSpace(customer.TaxNumber)
+	customer.Email = strings.TrimSpace(customer.Email)
+	if customer.Phone != "" {
+		customer.Phone, _ = NormalizePhone(customer.Phone)
 	}
 
 	// Varsayılan değerler
-	if customer.CustomerType == 0 {
-		customer.CustomerType = 1 // Bireysel
+	if customer.CustomerType == CustomerTypeAuto {
+		customer.CustomerType = CustomerTypeIndividual
+		if len(customer.TaxNumber) == 10 {
+			customer.CustomerType = CustomerTypeCorporate // VKN
+		}
 	}
-	if customer.SendingType == 0 {
-		customer.SendingType = 1 // Elektronik
+	if customer.SendingType == SendingTypeDefault {
+		customer.SendingType = SendingTypeElectronic
 	}
 	if customer.TaxOfficeID == "" {
 		customer.TaxOfficeID = "-1"
 	}
+	// Adlar boşsa ID'lerden doldurulur
+	if customer.CityName == "" {
+		customer.CityName = GetCityName(customer.CityID)
+	}
+	if customer.DistrictName == "" {
+		customer.DistrictName = GetDistrictName(customer.CityID, parseIntOrZero(customer.DistrictID))
+	}
 	if customer.BuildingNo == "" {
 		customer.BuildingNo = "1"
 	}
 
 	form := url.Values{
-		"AliciAdi":                   {customer.Name},
-		"Vnktckn":                    {customer.TaxNumber},
-		"Email":                      {customer.Email},
-		"Telefon":                    {customer.Phone},
-		"FaturaGonderimSekli":        {fmt.Sprintf("%d", customer.SendingType)},
-		"IdIl":                       {customer.CityID},
-		"IdIlce":                     {customer.DistrictID},
-		"IlAdi":                      {customer.CityName},
-		"IdVergiDairesi":             {customer.TaxOfficeID},
-		"SokakAdi":                   {customer.Address},
-		"BinaNo":                     {customer.BuildingNo},
-		"PostaKodu":                  {customer.PostalCode},
-		"AliciTipi":                  {fmt.Sprintf("%d", customer.CustomerType)},
-		"IdAliciTipi":                {"1"},
-		"IdFirma":                    {c.config.CompanyID},
-		"WebSite":                    {""},
-		"Fax":                        {""},
-		"Musterino":                  {""},
-		"IrsaliyeAlicisi":            {"false"},
-		"__RequestVerificationToken": {c.token},
-	}
-
-	req, err := http.NewRequest("POST", c.config.BaseURL+"/Recipient/Create", strings.NewReader(form.Encode()))
+		"AliciAdi":            {customer.Name},
+		"Vnktckn":             {customer.TaxNumber},
+		"Email":               {customer.Email},
+		"Telefon":             {customer.Phone},
+		"FaturaGonderimSekli": {fmt.Sprintf("%d", customer.SendingType)},
+		"IdIl":                {customer.CityID},
+		"IdIlce":              {customer.DistrictID},
+		"IlAdi":               {customer.CityName},
+		"IlceAdi":             {customer.DistrictName},
+		"IdVergiDairesi":      {customer.TaxOfficeID},
+		"SokakAdi":            {customer.Address},
+		"BinaNo":              {customer.BuildingNo},
+		"PostaKodu":           {customer.PostalCode},
+		"AliciTipi":           {fmt.Sprintf("%d", customer.CustomerType)},
+		"IdAliciTipi":         {"1"},
+		"IdFirma":             {c.companyID()},
+		"WebSite":             {""},
+		"Fax":                 {""},
+		"Musterino":           {""},
+		"IrsaliyeAlicisi":     {"false"},
+	}
+
+	body, err := c.postWithToken("/Invoice/CreateQuick", "/Recipient/Create", form, "müşteri oluşturma")
 	if err != nil {
-		return "", fmt.Errorf("request oluşturulamadı: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	return parseCustomerResult(body)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("müşteri oluşturma isteği başarısız: %w", err)
+// parseCustomerResult müşteri oluşturma yanıtını parse eder
+func parseCustomerResult(body []byte) (*CustomerResult, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("JSON parse hatası: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("response okunamadı: %w", err)
+	// Hata kontrolü
+	if errorMsg, ok := raw["error"].(string); ok && errorMsg != "" {
+		return nil, fmt.Errorf("müşteri oluşturma hatası: %s", errorMsg)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("JSON parse hatası: %w", err)
+	if errorMsg, ok := raw["ErrorMessage"].(string); ok && errorMsg != "" {
+		return nil, fmt.Errorf("müşteri oluşturma hatası: %s", errorMsg)
 	}
 
-	// Hata kontrolü
-	if errorMsg, ok := result["error"].(string); ok && errorMsg != "" {
-		return "", fmt.Errorf("müşteri oluşturma hatası: %s", errorMsg)
+	var result CustomerResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("JSON parse hatası: %w", err)
 	}
 
-	if errorMsg, ok := result["ErrorMessage"].(string); ok && errorMsg != "" {
-		return "", fmt.Errorf("müşteri oluşturma hatası: %s", errorMsg)
+	// Başarılı - ID'yi al
+	if _, ok := raw["IdAlici"].(float64); !ok {
+		return nil, fmt.Errorf("müşteri ID bulunamadı: %s", string(body))
 	}
+	result.CustomerID = fmt.Sprintf("%d", result.IdAlici)
 
-	// Başarılı - ID'yi al
-	if idAlici, ok := result["IdAlici"].(float64); ok {
-		return fmt.Sprintf("%.0f", idAlici), nil
+	// Uyarılar tek mesaj veya liste olarak gelebilir
+	if warning, ok := raw["WarningMessage"].(string); ok && warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+	if warnings, ok := raw["Warnings"].([]interface{}); ok {
+		for _, w := range warnings {
+			if warning, ok := w.(string); ok && warning != "" {
+				result.Warnings = append(result.Warnings, warning)
+			}
+		}
 	}
 
-	return "", fmt.Errorf("müşteri ID bulunamadı: %s", string(body))
+	result.Raw = raw
+	return &result, nil
 }
 
-// CreateInvoice fatura oluşturur
+// CreateInvoice fatura oluşturur ve fatura numarasını döner.
+// Invoice.Draft verilmişse taslak kaydedilir ve taslak ID'si döner.
 func (c *Client) CreateInvoice(invoice Invoice) (string, error) {
-	// Token güncelle
-	if err := c.updateToken("/Invoice/CreateQuick"); err != nil {
-		return "", fmt.Errorf("token güncellenemedi: %w", err)
+	// Validasyon
+	if err := c.validateInvoice(invoice); err != nil {
+		return "", err
+	}
+
+	// Aynı referansla kesilmiş fatura varsa tekrar kesilmez
+	if invoice.Reference != "" && !invoice.Draft {
+		existing, err := c.FindInvoiceByReference(invoice.Reference)
+		if err == nil {
+			return existing.InvoiceNumber, nil
+		}
+		if !errors.Is(err, ErrInvoiceNotFound) {
+			return "", fmt.Errorf("referans kontrolü başarısız: %w", err)
+		}
 	}
 
+	body, err := c.doInvoiceRequest(invoice)
+	if err != nil {
+		return "", err
+	}
+
+	// Başarılı response fatura numarasını (taslakta taslak ID'sini) string olarak döner
+	invoiceNo := strings.Trim(strings.TrimSpace(string(body)), `"`)
+	if invoiceNo == "" || strings.HasPrefix(invoiceNo, "{") || strings.Contains(invoiceNo, "error") {
+		return "", parseInvoiceError(body)
+	}
+
+	return invoiceNo, nil
+}
+
+// validateInvoice faturayı client ayarlarıyla (KDV oranları, not sayısı) ağ isteği yapmadan kontrol eder
+func (c *Client) validateInvoice(invoice Invoice) error {
+	if err := invoice.validate(c.config.AllowedVATRates, c.now()); err != nil {
+		return err
+	}
+
+	// Not sayısı varsayılan notlarla birlikte sınırlandığından ayrıca kontrol edilir
+	_, err := c.invoiceNotes(invoice)
+	return err
+}
+
+// doInvoiceRequest doğrulanmış faturayı portala gönderip ham yanıtı döner.
+// CreateInvoice ve CreateInvoiceRaw aynı isteği bu fonksiyonla yapar.
+func (c *Client) doInvoiceRequest(invoice Invoice) ([]byte, error) {
+	form, err := c.buildInvoiceForm(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	path, action := invoice.endpoint()
+	return c.postWithToken("/Invoice/CreateQuick", path, form, action)
+}
+
+// buildInvoiceForm boş tarih ve alıcı bilgilerini tamamlayıp portalın beklediği fatura formunu hazırlar
+func (c *Client) buildInvoiceForm(invoice Invoice) (url.Values, error) {
 	// Fatura tarihi
 	if invoice.Date.IsZero() {
-		invoice.Date = time.Now()
+		invoice.Date = c.now()
 	}
 
-	// Ürünleri hazırla
-	products := make([]map[string]interface{}, 0, len(invoice.Products))
-	var totalLineExtension float64
-	var totalVAT float64
-
-	for _, product := range invoice.Products {
-		lineTotal := product.Price * product.Quantity
-		vatAmount := lineTotal * float64(product.VATRate) / 100
-
-		totalLineExtension += lineTotal
-		totalVAT += vatAmount
-
-		products = append(products, map[string]interface{}{
-			"ProductInvoiceModelId":  0,
-			"DiscountAmount":         0,
-			"DiscountRate":           0,
-			"LineExtensionAmount":    lineTotal,
-			"MeasureUnitId":          c.config.MeasureUnit,
-			"ProductId":              nil,
-			"ProductName":            product.Name,
-			"Quantity":               product.Quantity,
-			"UnitPrice":              product.Price,
-			"VatAmount":              vatAmount,
-			"VatRate":                product.VATRate,
-			"AdditionalTaxes":        []interface{}{},
-			"WitholdingTaxes":        []interface{}{},
-			"Deleted":                false,
-			"DeliveryList":           []interface{}{},
-			"CustomsTrackingList":    []interface{}{},
-			"TaxExemptionReason":     "",
-			"TaxExemptionReasonCode": "",
-			"IdMensei":               0,
-			"Mensei":                 nil,
-			"SiniflandirmaKodu":      nil,
-			"IdSiniflandirmaKodu":    0,
-			"GTipNoArcvh":            "",
-		})
+	// Alıcı tipi, posta kutusu ve gönderim şekli
+	if err := c.resolveRecipient(&invoice); err != nil {
+		return nil, err
 	}
 
-	totalAmount := totalLineExtension + totalVAT
+	// Ürünleri hazırla
+	products, totals, err := c.buildProductLines(invoice)
+	if err != nil {
+		return nil, err
+	}
 
 	// Notes
-	notes := invoice.Notes
-	if len(notes) == 0 {
-		notes = []string{""}
+	notes, err := c.invoiceNotes(invoice)
+	if err != nil {
+		return nil, err
 	}
 
 	// Fatura JSON
 	invoiceData := map[string]interface{}{
 		"ETTN":                     "",
 		"InvoiceId":                "0",
-		"RecipientType":            "2",
+		"RecipientType":            invoice.RecipientType,
 		"InvoiceNumber":            "",
-		"CompanyId":                c.config.CompanyID,
+		"CompanyId":                c.companyID(),
 		"ScenarioType":             "0",
-		"ReceiverInboxTag":         nil,
+		"ReceiverInboxTag":         inboxTag(invoice.ReceiverInboxTag),
 		"InvoiceDate":              invoice.Date.Format("02-01-2006"),
-		"InvoiceTime":              invoice.Date.Format("15:04:05"),
-		"InvoiceType":              "1", // Satış faturası
+		"InvoiceTime":              c.invoiceClock(invoice),
+		"InvoiceType":              InvoiceTypeSales,
 		"LastPaymentDate":          "",
-		"DispatchList":             []interface{}{},
-		"IdAlici":                  invoice.CustomerID,
+		"OrderNumber":              strings.TrimSpace(invoice.Reference),
+		"DispatchList":             dispatchList(invoice.DispatchList),
+		"IdAlici":                  strings.TrimSpace(invoice.CustomerID),
 		"Products":                 products,
-		"CurrencyCode":             c.config.CurrencyCode,
-		"CrossRate":                0,
+		"CurrencyCode":             totals.CurrencyCode,
+		"CrossRate":                totals.CrossRate.Float64(),
 		"TaxExemptionReason":       "",
 		"Notes":                    notes,
-		"Receiver":                 map[string]string{"SendingType": "1"},
+		"Receiver":                 map[string]string{"SendingType": fmt.Sprintf("%d", invoice.SendingType)},
 		"IsFreeOfCharge":           false,
 		"KismiIadeMi":              false,
 		"CompanyBankAccountList":   []interface{}{},
-		"TotalLineExtensionAmount": totalLineExtension,
-		"TotalVATAmount":           totalVAT,
-		"TotalTaxInclusiveAmount":  totalAmount,
-		"TotalDiscountAmount":      0,
-		"TotalPayableAmount":       totalAmount,
-		"RoundCounter":             0,
+		"TotalLineExtensionAmount": totals.LineExtension.Float64(),
+		"TotalVATAmount":           totals.VAT.Float64(),
+		"TotalTaxInclusiveAmount":  totals.Total.Float64(),
+		"TotalDiscountAmount":      totals.Discount.Float64(),
+		"TotalPayableAmount":       totals.Payable.Float64(),
+		"RoundCounter":             totals.RoundAdjustment.Float64(),
+	}
+
+	// Ek alanlar varsayılanların üzerine yazar
+	for key, value := range invoice.ExtraFields {
+		invoiceData[key] = value
 	}
 
 	jsonData, err := json.Marshal(invoiceData)
 	if err != nil {
-		return "", fmt.Errorf("JSON marshal hatası: %w", err)
+		return nil, fmt.Errorf("JSON marshal hatası: %w", err)
 	}
 
-	form := url.Values{
-		"jsonData":                   {string(jsonData)},
-		"__RequestVerificationToken": {c.token},
+	return url.Values{
+		"jsonData": {string(jsonData)},
+	}, nil
+}
+
+// resolveRecipient verilmemişse alıcı tipini, posta kutusu etiketini ve gönderim şeklini
+// alıcı kaydı ile mükellef sorgusundan belirler. Sorgular yalnızca çağıranın boş bıraktığı
+// alanlar için yapılır. Sorgu başarısız olursa fatura durdurulmaz: gönderim şekli
+// elektronik, alıcı tipi e-arşiv varsayılır (alıcı tipinden emin olmak isteyen çağıran
+// CheckEInvoiceUser'ı kendisi çağırıp RecipientType'ı vermelidir).
+// Elektronik gönderimde kaydı alınan alıcının e-postası zorunludur.
+func (c *Client) resolveRecipient(invoice *Invoice) error {
+	// Etiket elle verildiyse alıcı e-fatura mükellefidir, sorgu gerekmez
+	if invoice.RecipientType == RecipientTypeAuto && invoice.ReceiverInboxTag != "" {
+		invoice.RecipientType = RecipientTypeEInvoice
+	}
+	if invoice.RecipientType != RecipientTypeAuto && invoice.SendingType != SendingTypeDefault {
+		return nil
 	}
 
-	req, err := http.NewRequest("POST", c.config.BaseURL+"/Invoice/Create", strings.NewReader(form.Encode()))
+	detail, err := c.GetRecipientDetail(parseIntOrZero(strings.TrimSpace(invoice.CustomerID)))
 	if err != nil {
-		return "", fmt.Errorf("request oluşturulamadı: %w", err)
+		detail = nil
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	if invoice.SendingType == SendingTypeDefault {
+		invoice.SendingType = SendingTypeElectronic
+		if detail != nil && detail.SendingType != SendingTypeDefault {
+			invoice.SendingType = detail.SendingType
+		}
+	}
+	if invoice.SendingType == SendingTypeElectronic && detail != nil && detail.Name != "" && detail.Email == "" {
+		return fmt.Errorf("elektronik gönderim için e-posta zorunludur: alıcı %s", invoice.CustomerID)
+	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("fatura oluşturma isteği başarısız: %w", err)
+	if invoice.RecipientType != RecipientTypeAuto {
+		return nil
+	}
+
+	// Varsayılan e-arşiv; VKN/TCKN'si bilinmeyen alıcı sorgulanamaz
+	invoice.RecipientType = RecipientTypeEArchive
+	if detail == nil || detail.TaxNumber == "" {
+		return nil
+	}
+
+	isEInvoiceUser, info, err := c.CheckEInvoiceUser(detail.TaxNumber)
+	if err != nil || !isEInvoiceUser {
+		return nil
+	}
+
+	invoice.RecipientType = RecipientTypeEInvoice
+	invoice.ReceiverInboxTag = info.DefaultAlias()
+	return nil
+}
+
+// AddNote faturaya yeni bir not satırı ekler
+func (i *Invoice) AddNote(note string) {
+	i.Notes = append(i.Notes, note)
+}
+
+// notePlaceholderRe not şablonundaki {ad} yer tutucuları
+var notePlaceholderRe = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// AddTemplatedNote şablondaki {ad} yer tutucularını vars ile doldurup not olarak ekler.
+// Değeri verilmeyen yer tutucu varsa not eklenmez ve hata döner.
+//
+//	invoice.AddTemplatedNote("Bu fatura {vade} tarihine kadar ödenmelidir.", map[string]string{"vade": "15.02.2024"})
+func (i *Invoice) AddTemplatedNote(template string, vars map[string]string) error {
+	var missing []string
+	note := notePlaceholderRe.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return placeholder
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return fmt.Errorf("not şablonunda değeri verilmeyen alanlar: %s", strings.Join(missing, ", "))
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	i.AddNote(note)
+	return nil
+}
+
+// normalizeNotes notları sırasını koruyarak kırpar ve boş satırları atar.
+// maxLength sıfırdan büyükse daha uzun notlar için hata döner.
+// Hiç not yoksa sunucunun beklediği tek boş satır döner.
+func normalizeNotes(notes []string, maxLength int) ([]string, error) {
+	result := make([]string, 0, len(notes))
+	for idx, note := range notes {
+		note = strings.TrimSpace(note)
+		if note == "" {
+			continue
+		}
+		if maxLength > 0 && len([]rune(note)) > maxLength {
+			return nil, fmt.Errorf("%d. not %d karakterden uzun olamaz", idx+1, maxLength)
+		}
+		result = append(result, note)
+	}
+
+	if len(result) == 0 {
+		return []string{""}, nil
+	}
+	return result, nil
+}
+
+// invoiceNotes faturanın notlarına varsayılan notları ekleyip normalleştirir
+func (c *Client) invoiceNotes(invoice Invoice) ([]string, error) {
+	notes := invoice.Notes
+	if !invoice.SkipDefaultNotes && len(c.config.DefaultNotes) > 0 {
+		notes = append(append([]string(nil), invoice.Notes...), c.config.DefaultNotes...)
+	}
+
+	normalized, err := normalizeNotes(notes, c.config.MaxNoteLength)
 	if err != nil {
-		return "", fmt.Errorf("response okunamadı: %w", err)
+		return nil, err
 	}
 
-	// Başarılı response fatura numarasını string olarak döner
-	invoiceNo := strings.Trim(string(body), `"`)
-	if invoiceNo == "" || strings.Contains(invoiceNo, "error") {
-		return "", fmt.Errorf("fatura oluşturulamadı: %s", string(body))
+	// Boş notlar atıldıktan sonra sayılır
+	if c.config.MaxNotes > 0 && len(normalized) > c.config.MaxNotes {
+		return nil, fmt.Errorf("fatura en fazla %d not satırı içerebilir, %d verildi (varsayılan notlar dahil)", c.config.MaxNotes, len(normalized))
 	}
+	return normalized, nil
+}
 
-	return invoiceNo, nil
+// inboxTag boş etiketi JSON'da null olarak gönderir
+func inboxTag(tag string) interface{} {
+	if tag == "" {
+		return nil
+	}
+	return tag
 }
 
-// CreateInvoiceRaw creates invoice and returns raw response body
-func (c *Client) CreateInvoiceRaw(invoice Invoice) ([]byte, error) {
-	if invoice.CustomerID == "" {
-		return nil, fmt.Errorf("müşteri ID gerekli")
+// DefaultVATRates Türkiye'de geçerli KDV oranlarını döner (%)
+func DefaultVATRates() []int {
+	return []int{0, 1, 10, 20}
+}
+
+// Validate faturayı göndermeden önce kontrol eder: sayısal müşteri ID, en az bir ürün,
+// ürün adı, sıfırdan farklı miktar, işaretli satırlar, geçerli KDV oranı ve makul tarih.
+// İndirim/düzeltme satırları için miktar veya fiyattan biri negatif verilebilir.
+func (i Invoice) Validate() error {
+	return i.validate(DefaultVATRates(), time.Now())
+}
+
+// validate faturayı verilen KDV oranlarıyla kontrol eder
+func (i Invoice) validate(allowedVATRates []int, now time.Time) error {
+	// Portal IdAlici alanını sayı olarak bekler, boş veya sayısal olmayan ID anlaşılmaz bir sunucu hatasına yol açar
+	customerID := strings.TrimSpace(i.CustomerID)
+	if customerID == "" {
+		return fmt.Errorf("müşteri ID gerekli")
+	}
+	if id, err := strconv.Atoi(customerID); err != nil || id <= 0 {
+		return fmt.Errorf("geçersiz müşteri ID: %q (portaldaki sayısal alıcı ID'si olmalı)", i.CustomerID)
+	}
+	if len(i.Products) == 0 {
+		return fmt.Errorf("en az bir ürün gerekli")
 	}
 
-	// Token güncelle
-	if err := c.updateToken("/Invoice/CreateQuick"); err != nil {
-		return nil, fmt.Errorf("token güncellenemedi: %w", err)
+	for idx, product := range i.Products {
+		if strings.TrimSpace(product.Name) == "" {
+			return fmt.Errorf("%d. satır: ürün adı zorunludur", idx+1)
+		}
+		if product.Quantity == 0 {
+			return fmt.Errorf("%d. satır (%s): miktar sıfır olamaz", idx+1, product.Name)
+		}
+		// Negatif satır (indirim/düzeltme) miktar veya fiyattan yalnızca biriyle belirtilir
+		if product.Quantity < 0 && product.unitPrice().Sign() < 0 {
+			return fmt.Errorf("%d. satır (%s): miktar ve fiyat birlikte negatif olamaz", idx+1, product.Name)
+		}
 	}
 
-	// Ürünleri hazırla
-	var products []map[string]interface{}
-	var totalLineExtension, totalVAT float64
+	if err := validateVATRates(i.Products, allowedVATRates); err != nil {
+		return err
+	}
+
+	// Tarih verilmemişse gönderim anı kullanılır
+	if !i.Date.IsZero() {
+		if i.Date.Year() < 2000 {
+			return fmt.Errorf("geçersiz fatura tarihi: %s", i.Date.Format("02-01-2006"))
+		}
+		if i.Date.After(now.AddDate(0, 0, 7)) {
+			return fmt.Errorf("fatura tarihi ileri bir tarih olamaz: %s", i.Date.Format("02-01-2006"))
+		}
+	}
+
+	if i.InvoiceTime != "" {
+		if _, ok := parseInvoiceTime(i.InvoiceTime); !ok {
+			return fmt.Errorf("geçersiz fatura saati: %q (SS:DD:ss biçiminde olmalı)", i.InvoiceTime)
+		}
+	}
+
+	if i.SendingType != SendingTypeDefault && i.SendingType != SendingTypeElectronic && i.SendingType != SendingTypePaper {
+		return fmt.Errorf("geçersiz gönderim şekli: %d (1=Elektronik, 2=Kağıt)", i.SendingType)
+	}
+
+	if tag := strings.TrimSpace(i.ReceiverInboxTag); tag != "" {
+		if !strings.HasPrefix(strings.ToLower(tag), "urn:mail:") {
+			return fmt.Errorf("geçersiz posta kutusu etiketi: %q (urn:mail: ile başlamalı)", i.ReceiverInboxTag)
+		}
+		if i.RecipientType == RecipientTypeEArchive {
+			return fmt.Errorf("posta kutusu etiketi yalnızca e-fatura alıcıları için verilebilir")
+		}
+	}
+
+	if i.CurrencyCode != "" && !IsValidCurrencyCode(i.CurrencyCode) {
+		return fmt.Errorf("geçersiz para birimi: %q (ISO 4217 kodu olmalı)", i.CurrencyCode)
+	}
+
+	if i.DiscountAmount < 0 || i.DiscountRate < 0 {
+		return fmt.Errorf("belge iskontosu negatif olamaz")
+	}
+	if i.DiscountAmount > 0 && i.DiscountRate > 0 {
+		return fmt.Errorf("belge iskontosu için tutar veya oran verilmelidir, ikisi birden verilemez")
+	}
+	if i.DiscountRate > 100 {
+		return fmt.Errorf("belge iskonto oranı %%100'den büyük olamaz: %g", i.DiscountRate)
+	}
+
+	for idx, ref := range i.DispatchList {
+		if strings.TrimSpace(ref.Number) == "" {
+			return fmt.Errorf("%d. irsaliye: irsaliye numarası zorunludur", idx+1)
+		}
+		if ref.Date.IsZero() {
+			return fmt.Errorf("%d. irsaliye (%s): irsaliye tarihi zorunludur", idx+1, ref.Number)
+		}
+	}
+
+	return nil
+}
+
+// endpoint faturanın gönderileceği yolu ve hata mesajlarındaki işlem adını döner
+func (i Invoice) endpoint() (path, action string) {
+	if i.Draft {
+		return "/Invoice/SaveDraft", "taslak kaydetme"
+	}
+	return "/Invoice/Create", "fatura oluşturma"
+}
+
+// validateVATRates her ürünün KDV oranının izin verilen oranlardan biri olduğunu kontrol eder
+func validateVATRates(products []Product, allowed []int) error {
+	for i, product := range products {
+		if !containsInt(allowed, product.VATRate) {
+			return fmt.Errorf("%d. satır (%s): geçersiz KDV oranı %%%d, geçerli oranlar: %v", i+1, product.Name, product.VATRate, allowed)
+		}
+	}
+	return nil
+}
+
+// containsInt slice içinde değer var mı
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// unitPrice ürünün KDV hariç birim fiyatını ondalık olarak döner
+func (p Product) unitPrice() Money {
+	if !p.ExactPrice.IsZero() {
+		return p.ExactPrice
+	}
+	return NewMoney(p.Price)
+}
+
+// InvoiceTotals faturanın portala gönderilen toplamları
+type InvoiceTotals struct {
+	LineExtension   Money  // KDV hariç toplam (yuvarlanmış satır tutarlarının toplamı)
+	VAT             Money  // Toplam KDV
+	Total           Money  // KDV dahil toplam
+	Discount        Money  // Belge iskontosu
+	Payable         Money  // Ödenecek tutar (iskonto düşülmüş, WithRoundTotalTo verilmişse yuvarlanmış)
+	RoundAdjustment Money  // WithRoundTotalTo yuvarlamasının ödenecek tutara eklediği fark (RoundCounter), yoksa sıfır
+	CurrencyCode    string // Fatura para birimi
+	CrossRate       Money  // TRY için sıfır
+}
+
+// buildProductLines ürün satırlarını ondalık aritmetikle yuvarlayarak hazırlar ve
+// belge iskontosu dahil toplamları hesaplar
+func (c *Client) buildProductLines(invoice Invoice) ([]map[string]interface{}, InvoiceTotals, error) {
+	products := make([]map[string]interface{}, 0, len(invoice.Products))
+	var totals InvoiceTotals
+
+	currency, rate, err := c.invoiceCurrency(invoice)
+	if err != nil {
+		return nil, totals, err
+	}
 
 	for _, product := range invoice.Products {
-		lineTotal := product.Price * product.Quantity
-		vatAmount := lineTotal * float64(product.VATRate) / 100
-		totalLineExtension += lineTotal
-		totalVAT += vatAmount
-
-		products = append(products, map[string]interface{}{
-			"ProductInvoiceModelId":  0,
-			"DiscountAmount":         0,
-			"DiscountRate":           0,
-			"LineExtensionAmount":    lineTotal,
-			"MeasureUnitId":          c.config.MeasureUnit,
-			"ProductId":              nil,
-			"ProductName":            product.Name,
-			"Quantity":               product.Quantity,
-			"UnitPrice":              product.Price,
-			"VatAmount":              vatAmount,
-			"VatRate":                product.VATRate,
-			"AdditionalTaxes":        []interface{}{},
-			"WitholdingTaxes":        []interface{}{},
-			"Deleted":                false,
-			"DeliveryList":           []interface{}{},
-			"CustomsTrackingList":    []interface{}{},
-			"TaxExemptionReason":     "",
-			"TaxExemptionReasonCode": "",
-			"IdMensei":               0,
-			"Mensei":                 nil,
-			"SiniflandirmaKodu":      nil,
-			"IdSiniflandirmaKodu":    0,
-			"GTipNoArcvh":            "",
-		})
+		price := product.unitPrice()
+		// TL fiyatlar kur ile fatura para birimine çevrilir, yuvarlama satır tutarında yapılır
+		if invoice.PricesInTRY {
+			price = price.Div(rate)
+		}
+		rawLine := price.Mul(NewMoney(product.Quantity))
+		rawVAT := rawLine.MulRate(product.VATRate) // Negatif satırda KDV de negatiftir
+
+		// Satırlar toplanmadan önce yuvarlanır
+		lineTotal := c.round(rawLine)
+		vatAmount := c.round(rawVAT)
+
+		totals.LineExtension = totals.LineExtension.Add(lineTotal)
+		totals.VAT = totals.VAT.Add(vatAmount)
+
+		products = append(products, c.productLine(product, price, lineTotal, vatAmount))
 	}
 
-	totalAmount := totalLineExtension + totalVAT
+	totals.Total = totals.LineExtension.Add(totals.VAT)
+	totals.CurrencyCode = currency
+	totals.CrossRate = rate
 
-	// Notes
-	notes := invoice.Notes
-	if len(notes) == 0 {
-		notes = []string{""}
+	// Negatif satırlar toplamı düşürür ancak fatura toplamı negatif olamaz
+	if totals.Total.Sign() < 0 {
+		return nil, totals, fmt.Errorf("fatura toplamı negatif olamaz: %s", totals.Total)
 	}
 
-	// Fatura JSON - CreateInvoice ile aynı format
-	invoiceData := map[string]interface{}{
-		"ETTN":                     "",
-		"InvoiceId":                "0",
-		"RecipientType":            "2",
-		"InvoiceNumber":            "",
-		"CompanyId":                c.config.CompanyID,
-		"ScenarioType":             "0",
-		"ReceiverInboxTag":         nil,
-		"InvoiceDate":              invoice.Date.Format("02-01-2006"),
-		"InvoiceTime":              invoice.Date.Format("15:04:05"),
-		"InvoiceType":              "1", // Satış faturası
-		"LastPaymentDate":          "",
-		"DispatchList":             []interface{}{},
-		"IdAlici":                  invoice.CustomerID,
-		"Products":                 products,
-		"CurrencyCode":             c.config.CurrencyCode,
-		"CrossRate":                0,
-		"TaxExemptionReason":       "",
-		"Notes":                    notes,
-		"Receiver":                 map[string]string{"SendingType": "1"},
-		"IsFreeOfCharge":           false,
-		"KismiIadeMi":              false,
-		"CompanyBankAccountList":   []interface{}{},
-		"TotalLineExtensionAmount": totalLineExtension,
-		"TotalVATAmount":           totalVAT,
-		"TotalTaxInclusiveAmount":  totalAmount,
-		"TotalDiscountAmount":      0,
-		"TotalPayableAmount":       totalAmount,
-		"RoundCounter":             0,
+	// Belge iskontosu satırlara dağıtılmaz, yalnızca ödenecek tutardan düşülür
+	switch {
+	case invoice.DiscountRate > 0:
+		totals.Discount = c.round(totals.Total.Mul(NewMoney(invoice.DiscountRate)).Div(NewMoney(100)))
+	case invoice.DiscountAmount > 0:
+		totals.Discount = c.round(NewMoney(invoice.DiscountAmount))
+	}
+	if totals.Discount.Cmp(totals.Total) > 0 {
+		return nil, totals, fmt.Errorf("belge iskontosu (%s) fatura toplamını (%s) aşamaz", totals.Discount, totals.Total)
+	}
+
+	totals.Payable = totals.Total.Sub(totals.Discount)
+
+	// Ödenecek tutar verilen adıma yuvarlanır, fark yuvarlama satırına yazılır.
+	// Satır yuvarlamasından doğan kayma toplamlara zaten yansıdığından ayrıca gönderilmez.
+	if c.config.RoundTotalTo > 0 {
+		step := NewMoney(c.config.RoundTotalTo)
+		discounted := totals.Payable
+		totals.Payable = discounted.Div(step).Round(0, c.config.RoundingMode).Mul(step)
+		totals.RoundAdjustment = totals.Payable.Sub(discounted)
+	}
+
+	return products, totals, nil
+}
+
+// ComputeTotals faturanın toplamlarını CreateInvoice ile aynı hesapla, fatura oluşturmadan
+// döner (örn. arayüzde gönderim öncesi toplam göstermek için). Client'ın yuvarlama, para
+// birimi ve WithRoundTotalTo ayarları ile belge iskontosu uygulanır. Ağ isteği yapılmaz;
+// müşteri ve tarih kontrol edilmez, bu yüzden eksik doldurulmuş faturalarla da çağrılabilir.
+func (c *Client) ComputeTotals(invoice Invoice) (*InvoiceTotals, error) {
+	_, totals, err := c.buildProductLines(invoice)
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// ComputeInvoiceTotals ürünlerin toplamlarını varsayılan client ayarlarıyla (TRY, 2 basamak,
+// RoundHalfUp) hesaplar. Farklı ayarlar veya belge iskontosu için Client.ComputeTotals kullanılır.
+func ComputeInvoiceTotals(products []Product) (*InvoiceTotals, error) {
+	c := &Client{config: &Config{
+		CurrencyCode:     "TRY",
+		RoundingDecimals: 2,
+		RoundingMode:     RoundHalfUp,
+	}}
+	return c.ComputeTotals(Invoice{Products: products})
+}
+
+// now client saatine göre şimdiki zamanı client saat diliminde döner (bkz. WithClock, WithLocation)
+func (c *Client) now() time.Time {
+	now := time.Now()
+	if c.config.Clock != nil {
+		now = c.config.Clock()
 	}
+	if c.config.Location != nil {
+		now = now.In(c.config.Location)
+	}
+	return now
+}
+
+// invoiceTimeLayouts Invoice.InvoiceTime için kabul edilen biçimler
+var invoiceTimeLayouts = []string{"15:04:05", "15:04"}
+
+// parseInvoiceTime Invoice.InvoiceTime değerini parse eder
+func parseInvoiceTime(text string) (time.Time, bool) {
+	text = strings.TrimSpace(text)
+	for _, layout := range invoiceTimeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// invoiceClock faturanın saatini belirler: önce InvoiceTime, sonra Date'in saat bileşeni;
+// Date yalnızca gün içeriyorsa (00:00:00) client saat diliminde şimdiki saat kullanılır
+func (c *Client) invoiceClock(invoice Invoice) string {
+	if t, ok := parseInvoiceTime(invoice.InvoiceTime); ok {
+		return t.Format("15:04:05")
+	}
+	if hour, minute, sec := invoice.Date.Clock(); hour != 0 || minute != 0 || sec != 0 {
+		return invoice.Date.Format("15:04:05")
+	}
+	return c.now().Format("15:04:05")
+}
 
-	jsonData, err := json.Marshal(invoiceData)
-	if err != nil {
-		return nil, fmt.Errorf("JSON marshal hatası: %w", err)
+// invoiceCurrency faturanın para birimini ve kurunu belirleyip tutarlılığını kontrol eder
+func (c *Client) invoiceCurrency(invoice Invoice) (string, Money, error) {
+	currency := strings.ToUpper(strings.TrimSpace(invoice.CurrencyCode))
+	if currency == "" {
+		currency = strings.ToUpper(c.config.CurrencyCode)
+	}
+	if !IsValidCurrencyCode(currency) {
+		return "", Money{}, fmt.Errorf("geçersiz para birimi: %q (ISO 4217 kodu olmalı)", currency)
+	}
+	if invoice.CrossRate < 0 {
+		return "", Money{}, fmt.Errorf("döviz kuru negatif olamaz: %g", invoice.CrossRate)
 	}
 
-	form := url.Values{
-		"jsonData":                   {string(jsonData)},
-		"__RequestVerificationToken": {c.token},
+	if currency == "TRY" {
+		if invoice.CrossRate != 0 && invoice.CrossRate != 1 {
+			return "", Money{}, fmt.Errorf("TRY faturada döviz kuru verilemez: %g", invoice.CrossRate)
+		}
+		if invoice.PricesInTRY {
+			return "", Money{}, fmt.Errorf("PricesInTRY yalnızca döviz faturalarında kullanılabilir")
+		}
+		return currency, Money{}, nil
 	}
 
-	req, err := http.NewRequest("POST", c.config.BaseURL+"/Invoice/Create", strings.NewReader(form.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+	if invoice.CrossRate == 0 {
+		return "", Money{}, fmt.Errorf("%s fatura için döviz kuru (CrossRate) zorunludur", currency)
 	}
+	return currency, NewMoney(invoice.CrossRate), nil
+}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+// round client yuvarlama ayarına göre tutarı yuvarlar
+func (c *Client) round(value Money) Money {
+	return value.Round(c.config.RoundingDecimals, c.config.RoundingMode)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("fatura oluşturma isteği başarısız: %w", err)
+// productLine fatura JSON'ı için ürün satırını hazırlar
+func (c *Client) productLine(product Product, price, lineTotal, vatAmount Money) map[string]interface{} {
+	// Katalog ürünü verilmişse ID ile referans ver
+	var productID interface{}
+	if product.ProductID != "" {
+		productID = parseIntOrZero(product.ProductID)
+	}
+
+	// Menşei ID verilip ad verilmemişse veri setinden tamamla
+	var mensei interface{}
+	if product.CountryOfOrigin != "" {
+		mensei = product.CountryOfOrigin
+	} else if name := GetMenseiName(product.CountryOfOriginID); name != "-1" {
+		mensei = name
+	}
+
+	var classificationCode interface{}
+	if product.ClassificationCode != "" {
+		classificationCode = product.ClassificationCode
+	}
+
+	return map[string]interface{}{
+		"ProductInvoiceModelId":  0,
+		"DiscountAmount":         0,
+		"DiscountRate":           0,
+		"LineExtensionAmount":    lineTotal.Float64(),
+		"MeasureUnitId":          c.config.MeasureUnit,
+		"ProductId":              productID,
+		"ProductName":            product.Name,
+		"Quantity":               product.Quantity,
+		"UnitPrice":              price.Float64(),
+		"VatAmount":              vatAmount.Float64(),
+		"VatRate":                product.VATRate,
+		"AdditionalTaxes":        []interface{}{},
+		"WitholdingTaxes":        []interface{}{},
+		"Deleted":                false,
+		"DeliveryList":           []interface{}{},
+		"CustomsTrackingList":    []interface{}{},
+		"TaxExemptionReason":     "",
+		"TaxExemptionReasonCode": "",
+		"IdMensei":               product.CountryOfOriginID,
+		"Mensei":                 mensei,
+		"SiniflandirmaKodu":      classificationCode,
+		"IdSiniflandirmaKodu":    product.ClassificationID,
+		"GTipNoArcvh":            product.GTIPCode,
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("response okunamadı: %w", err)
+// CreateInvoiceRaw CreateInvoice ile aynı faturayı gönderir ancak yanıtı parse etmeden döner.
+// Yanıt ham döndüğünden Reference ile mükerrer fatura kontrolü yapılmaz.
+func (c *Client) CreateInvoiceRaw(invoice Invoice) ([]byte, error) {
+	// Validasyon
+	if err := c.validateInvoice(invoice); err != nil {
+		return nil, err
 	}
 
-	return body, nil
+	return c.doInvoiceRequest(invoice)
 }
 
 // CreateInvoiceWithCustomer müşteri yoksa oluşturur ve fatura keser
 func (c *Client) CreateInvoiceWithCustomer(customer *Customer, products []Product) (string, error) {
-	// Müşteri ID varsa direkt fatura oluştur
-	customerID := ""
+	result, err := c.CreateInvoiceWithCustomerDetailed(customer, products)
+	if err != nil {
+		return "", err
+	}
 
-	// Müşteri bilgisi verilmişse önce müşteri oluştur veya mevcut olanı bul
-	if customer != nil {
-		id, err := c.CreateCustomerOrGetExisting(*customer)
-		if err != nil {
-			return "", fmt.Errorf("müşteri işlemi başarısız: %w", err)
-		}
-		customerID = id
-	} else {
-		return "", fmt.Errorf("müşteri bilgisi gerekli")
+	return result.Invoice.InvoiceNumber, nil
+}
+
+// CreateInvoiceWithCustomerDetailed müşteri yoksa oluşturur, fatura keser ve
+// kullanılan müşteri ID'sini fatura sonucuyla birlikte döner
+func (c *Client) CreateInvoiceWithCustomerDetailed(customer *Customer, products []Product) (*CustomerInvoiceResult, error) {
+	if customer == nil {
+		return nil, fmt.Errorf("müşteri bilgisi gerekli")
+	}
+
+	// Önce müşteri oluştur veya mevcut olanı bul
+	match, err := c.CreateCustomerOrGetExistingDetailed(*customer)
+	if err != nil && !errors.Is(err, ErrAmbiguousMatch) {
+		return nil, fmt.Errorf("müşteri işlemi başarısız: %w", err)
 	}
 
 	// Fatura oluştur
 	invoice := Invoice{
-		CustomerID: customerID,
+		CustomerID: match.CustomerID,
 		Products:   products,
-		Date:       time.Now(),
+		Date:       c.now(),
 	}
 
 	invoiceNo, err := c.CreateInvoice(invoice)
 	if err != nil {
-		return "", fmt.Errorf("fatura oluşturulamadı: %w", err)
+		return nil, fmt.Errorf("fatura oluşturulamadı: %w", err)
 	}
 
-	return invoiceNo, nil
+	return &CustomerInvoiceResult{
+		CustomerID: match.CustomerID,
+		WasCreated: match.Created,
+		Invoice:    &InvoiceResult{InvoiceNumber: invoiceNo},
+	}, nil
+}
+
+// LineWithGrossPrice KDV dahil birim fiyatla verilen fatura satırı
+type LineWithGrossPrice struct {
+	Name       string
+	Quantity   float64
+	GrossPrice float64 // KDV dahil birim fiyat
+	VATRate    int     // KDV oranı (%)
+}
+
+// QuickInvoice KDV dahil fiyatlı satırlardan fatura keser. KDV hariç birim fiyat
+// her satır için kesin olarak geri hesaplanır; müşteri yoksa oluşturulur.
+func (c *Client) QuickInvoice(customer *Customer, lines []LineWithGrossPrice) (*CustomerInvoiceResult, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("en az bir ürün gerekli")
+	}
+
+	products := make([]Product, 0, len(lines))
+	for _, line := range lines {
+		net := CalculatePriceWithoutVATExact(NewMoney(line.GrossPrice), line.VATRate)
+		products = append(products, Product{
+			Name:       line.Name,
+			Quantity:   line.Quantity,
+			Price:      net.Float64(),
+			ExactPrice: net,
+			VATRate:    line.VATRate,
+		})
+	}
+
+	return c.CreateInvoiceWithCustomerDetailed(customer, products)
 }
 
 // updateToken sayfadan CSRF token alır
 func (c *Client) updateToken(path string) error {
-	resp, err := c.httpClient.Get(c.config.BaseURL + path)
+	req, err := c.newRequest("GET", c.config.BaseURL+path, nil)
 	if err != nil {
 		return err
 	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("token isteği başarısız: %w", err)
+	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token sayfası alınamadı (%s), status: %d", path, resp.StatusCode)
+	}
+
+	body, err := c.readBody(resp.Body)
 	if err != nil {
 		return err
 	}
 
-	// Token regex
-	re := regexp.MustCompile(`name="__RequestVerificationToken".*?value="([^"]+)"`)
-	matches := re.FindStringSubmatch(string(body))
-	if len(matches) < 2 {
-		return fmt.Errorf("token bulunamadı")
+	// Oturum kapalıysa sayfa giriş formuna yönlendirilir; giriş formundaki token bu sayfa için geçersizdir
+	loginPage := strings.EqualFold(path, "/account/login")
+	if !loginPage && (strings.EqualFold(resp.Request.URL.Path, "/account/login") || isLoginForm(string(body))) {
+		return fmt.Errorf("%w: %s giriş sayfasına yönlendirdi", ErrSessionExpired, path)
 	}
 
-	c.token = matches[1]
+	token, ok := extractToken(string(body))
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTokenNotFound, path)
+	}
+
+	c.token.mu.Lock()
+	c.token.value = token
+	c.token.fetchedAt = time.Now()
+	c.token.mu.Unlock()
 	return nil
 }
 
-// GetRecipientList müşteri listesini pagination ile getirir
-func (c *Client) GetRecipientList(start, length int) (*RecipientListResponse, error) {
+var (
+	inputTagRe  = regexp.MustCompile(`(?is)<input\b[^>]*>`)
+	attributeRe = regexp.MustCompile(`(?s)([\w:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// extractToken HTML içindeki __RequestVerificationToken input değerini bulur.
+// Attribute sırası, tırnak tipi ve boşluk/satır sonlarından bağımsızdır.
+func extractToken(html string) (string, bool) {
+	for _, tag := range inputTagRe.FindAllString(html, -1) {
+		var name, value string
+		hasValue := false
+
+		for _, attr := range attributeRe.FindAllStringSubmatch(tag, -1) {
+			val := attr[2] + attr[3] + attr[4]
+			switch strings.ToLower(attr[1]) {
+			case "name":
+				name = val
+			case "value":
+				value = val
+				hasValue = true
+			}
+		}
+
+		if name == "__RequestVerificationToken" && hasValue && value != "" {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// ensureToken önbellekteki token süresi dolmuşsa sayfadan yeniden alır
+func (c *Client) ensureToken(path string) error {
+	c.token.mu.Lock()
+	fresh := c.token.value != "" && c.config.TokenTTL > 0 && time.Since(c.token.fetchedAt) < c.config.TokenTTL
+	c.token.mu.Unlock()
+
+	if fresh {
+		return nil
+	}
+	return c.updateToken(path)
+}
+
+// invalidateToken önbellekteki token'ı geçersiz kılar
+func (c *Client) invalidateToken() {
+	c.token.mu.Lock()
+	c.token.value = ""
+	c.token.fetchedAt = time.Time{}
+	c.token.mu.Unlock()
+}
+
+// currentToken son alınan CSRF token'ı döner
+func (c *Client) currentToken() string {
+	c.token.mu.Lock()
+	defer c.token.mu.Unlock()
+	return c.token.value
+}
+
+// postWithToken formu CSRF token ile AJAX isteği olarak gönderir ve response body'sini döner.
+// Sunucu token'ı reddederse token tokenPath'ten yenilenip istek bir kez tekrarlanır.
+func (c *Client) postWithToken(tokenPath, path string, form url.Values, action string) ([]byte, error) {
+	return c.postBodyWithToken(tokenPath, path, action, func(token string) (io.Reader, string, error) {
+		form.Set("__RequestVerificationToken", token)
+		return strings.NewReader(form.Encode()), "application/x-www-form-urlencoded; charset=UTF-8", nil
+	})
+}
+
+// postBodyWithToken postWithToken'ın gövde biçiminden bağımsız hali: build her denemede
+// güncel token ile istek gövdesini ve Content-Type'ı üretir (örn. multipart dosya yükleme)
+func (c *Client) postBodyWithToken(tokenPath, path, action string, build func(token string) (io.Reader, string, error)) ([]byte, error) {
+	if err := c.ensureToken(tokenPath); err != nil {
+		return nil, fmt.Errorf("token güncellenemedi: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		reqBody, contentType, err := build(c.currentToken())
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := c.newRequest("POST", c.config.BaseURL+path, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+		}
+
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s isteği başarısız: %w", action, err)
+		}
+
+		body, err := c.readBody(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("response okunamadı: %w", err)
+		}
+
+		if attempt > 0 || !isTokenError(resp.StatusCode, body) {
+			return body, nil
+		}
+
+		// Token reddedildi - yenile ve tekrar dene
+		c.invalidateToken()
+		if err := c.updateToken(tokenPath); err != nil {
+			return nil, fmt.Errorf("token güncellenemedi: %w", err)
+		}
+	}
+}
+
+// isTokenError response'un anti-forgery token doğrulama hatası olup olmadığını kontrol eder
+func isTokenError(statusCode int, body []byte) bool {
+	if statusCode != http.StatusBadRequest && statusCode != http.StatusInternalServerError {
+		return false
+	}
+	text := strings.ToLower(string(body))
+	return strings.Contains(text, "antiforgery") ||
+		strings.Contains(text, "anti-forgery") ||
+		strings.Contains(text, "__requestverificationtoken")
+}
+
+// RecipientSortColumn müşteri listesinin sıralanabileceği kolon
+type RecipientSortColumn int
+
+const (
+	RecipientSortByID        RecipientSortColumn = iota // IdAlici; kayıt (oluşturulma) sırası
+	RecipientSortByName                                 // AliciAdi
+	RecipientSortByTaxNumber                            // Vnktckn
+	RecipientSortByState                                // StateName
+)
+
+// recipientSortColumnIndex sıralama kolonunun DataTables kolon indeksini döner
+func recipientSortColumnIndex(column RecipientSortColumn) (int, bool) {
+	switch column {
+	case RecipientSortByID:
+		return 0, true
+	case RecipientSortByName:
+		return 2, true
+	case RecipientSortByTaxNumber:
+		return 3, true
+	case RecipientSortByState:
+		return 4, true
+	}
+	return 0, false
+}
+
+// RecipientOrder müşteri listesi sıralaması
+type RecipientOrder struct {
+	Column     RecipientSortColumn
+	Descending bool
+}
+
+// RecipientFilter müşteri listesi filtresi; sıfır değeri tüm aktif müşterileri getirir
+type RecipientFilter struct {
+	Type  CustomerType   // CustomerTypeIndividual, CustomerTypeCorporate; 0 ise tümü
+	State RecipientState // RecipientStateActive, RecipientStatePassive; 0 ise aktif
+}
+
+// GetRecipientList aktif müşteri listesini pagination ile getirir. order verilmezse portalın
+// varsayılan sırası kullanılır; birden fazla verilirse sırayla ikincil sıralama olur.
+func (c *Client) GetRecipientList(start, length int, order ...RecipientOrder) (*RecipientListResponse, error) {
+	return c.GetRecipientListFiltered(start, length, RecipientFilter{}, order...)
+}
+
+// GetRecipientListFiltered müşteri listesini alıcı tipi ve durumuna göre filtreleyerek getirir
+func (c *Client) GetRecipientListFiltered(start, length int, filter RecipientFilter, order ...RecipientOrder) (*RecipientListResponse, error) {
+	if filter.Type != CustomerTypeAuto && filter.Type != CustomerTypeIndividual && filter.Type != CustomerTypeCorporate {
+		return nil, fmt.Errorf("geçersiz alıcı tipi: %d (1=Bireysel, 2=Kurumsal)", filter.Type)
+	}
+	state := filter.State
+	if state == RecipientStateDefault {
+		state = RecipientStateActive
+	}
+	if state != RecipientStateActive && state != RecipientStatePassive {
+		return nil, fmt.Errorf("geçersiz müşteri durumu: %d (1=Aktif, 2=Pasif)", filter.State)
+	}
+
 	// Form data for recipient list
 	form := url.Values{
 		"draw":            {"1"},
@@ -625,9 +1943,9 @@ func (c *Client) GetRecipientList(start, length int) (*RecipientListResponse, er
 		"length":          {fmt.Sprintf("%d", length)},
 		"search[value]":   {""},
 		"search[regex]":   {"false"},
-		"AliciTipi":       {"0"},
-		"CompanyIdFilter": {c.config.CompanyID},
-		"RecipientState":  {"1"},
+		"AliciTipi":       {fmt.Sprintf("%d", filter.Type)},
+		"CompanyIdFilter": {c.companyID()},
+		"RecipientState":  {fmt.Sprintf("%d", state)},
 	}
 
 	// Columns configuration
@@ -654,7 +1972,22 @@ func (c *Client) GetRecipientList(start, length int) (*RecipientListResponse, er
 		form.Add(fmt.Sprintf("columns[%d][search][regex]", i), "false")
 	}
 
-	req, err := http.NewRequest("POST", c.config.BaseURL+"/Recipient/GetRecipientList", strings.NewReader(form.Encode()))
+	// Sıralama
+	for i, o := range order {
+		index, ok := recipientSortColumnIndex(o.Column)
+		if !ok {
+			return nil, fmt.Errorf("geçersiz sıralama kolonu: %d", o.Column)
+		}
+		dir := "asc"
+		if o.Descending {
+			dir = "desc"
+		}
+		form.Set(fmt.Sprintf("columns[%d][orderable]", index), "true")
+		form.Add(fmt.Sprintf("order[%d][column]", i), fmt.Sprintf("%d", index))
+		form.Add(fmt.Sprintf("order[%d][dir]", i), dir)
+	}
+
+	req, err := c.newRequest("POST", c.config.BaseURL+"/Recipient/GetRecipientList", strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
 	}
@@ -668,24 +2001,108 @@ func (c *Client) GetRecipientList(start, length int) (*RecipientListResponse, er
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	// Büyük listeler belleğe ayrıca kopyalanmadan doğrudan parse edilir
+	var result RecipientListResponse
+	if err := json.NewDecoder(c.limitBody(resp.Body)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetAllRecipients tüm müşterileri sayfa sayfa getirip her biri için fn'i çağırır.
+// Liste belleğe toplanmaz; fn hata dönerse dolaşma durur ve o hata döner.
+func (c *Client) GetAllRecipients(fn func(RecipientListItem) error) error {
+	start := 0
+	length := 200
+
+	for {
+		page, err := c.GetRecipientList(start, length)
+		if err != nil {
+			return err
+		}
+
+		for _, recipient := range page.Data {
+			if err := fn(recipient); err != nil {
+				return err
+			}
+		}
+
+		// Eğer gelen veri sayısı length'ten azsa, tüm veri alındı
+		if len(page.Data) < length {
+			return nil
+		}
+
+		// Sonraki sayfa
+		start += length
+	}
+}
+
+// ErrRecipientNotFound müşteri portalda bulunamadı
+var ErrRecipientNotFound = errors.New("müşteri bulunamadı")
+
+// GetRecipientJSON müşteri kaydını portalın JSON uç noktasından yapılandırılmış olarak getirir.
+// HTML detay sayfasını ayrıştıran GetRecipientDetail'e göre tüm alanları eksiksiz döner.
+func (c *Client) GetRecipientJSON(recipientID int) (*RecipientListItem, error) {
+	if recipientID <= 0 {
+		return nil, fmt.Errorf("müşteri ID gerekli")
+	}
+
+	endpoint := fmt.Sprintf("%s/Recipient/GetRecipient?RecipientId=%d", c.config.BaseURL, recipientID)
+
+	req, err := c.newRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("müşteri detay isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %d", ErrRecipientNotFound, recipientID)
+	}
+
+	body, err := c.readBody(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("response okunamadı: %w", err)
 	}
 
-	var result RecipientListResponse
-	if err := json.Unmarshal(body, &result); err != nil {
+	var recipient *RecipientListItem
+	if err := json.Unmarshal(body, &recipient); err != nil {
 		return nil, fmt.Errorf("JSON parse hatası: %w", err)
 	}
+	if recipient == nil || recipient.IdAlici == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrRecipientNotFound, recipientID)
+	}
 
-	return &result, nil
+	return recipient, nil
+}
+
+// GetRecipientByID müşteriyi Customer olarak getirir. Önce JSON uç noktası denenir,
+// uç nokta kullanılamıyorsa HTML detay sayfasına (GetRecipientDetail) düşülür.
+func (c *Client) GetRecipientByID(recipientID int) (*Customer, error) {
+	recipient, err := c.GetRecipientJSON(recipientID)
+	if err == nil {
+		customer := recipient.ToCustomer()
+		return &customer, nil
+	}
+	if errors.Is(err, ErrRecipientNotFound) {
+		return nil, err
+	}
+
+	return c.GetRecipientDetail(recipientID)
 }
 
 // GetRecipientDetail müşteri detaylarını getirir
 func (c *Client) GetRecipientDetail(recipientID int) (*Customer, error) {
 	url := fmt.Sprintf("%s/Recipient/Detail?RecipientId=%d", c.config.BaseURL, recipientID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := c.newRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
 	}
@@ -698,11 +2115,15 @@ func (c *Client) GetRecipientDetail(recipientID int) (*Customer, error) {
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.readBody(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("response okunamadı: %w", err)
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("müşteri detayı alınamadı (%d), status: %d", recipientID, resp.StatusCode)
+	}
+
 	// HTML parse - extract customer data
 	htmlStr := string(body)
 	customer := &Customer{}
@@ -748,12 +2169,38 @@ func (c *Client) GetRecipientDetail(recipientID int) (*Customer, error) {
 		customer.CityName = strings.TrimSpace(matches[2])
 	}
 
+	// Extract customer type and sending type (selected option)
+	if value := selectedOptionValue(htmlStr, "AliciTipi"); value != "" {
+		customer.CustomerType = CustomerType(parseIntOrZero(value))
+	}
+	if value := selectedOptionValue(htmlStr, "FaturaGonderimSekli"); value != "" {
+		customer.SendingType = SendingType(parseIntOrZero(value))
+	}
+
 	// Extract district (would need another request as it's dynamically loaded)
 	// For now, we'll leave district empty
 
 	return customer, nil
 }
 
+// SimilarityScore iki string arasındaki normalize edilmiş Levenshtein benzerliğini döner (0-1 arası).
+// Karşılaştırma büyük/küçük harf ve baştaki/sondaki boşluklardan bağımsızdır;
+// SimilarityLevenshtein ile müşteri eşleştirmede kullanılan skorun aynısıdır.
+func SimilarityScore(s1, s2 string) float64 {
+	return calculateSimilarityScore(s1, s2)
+}
+
+// EditDistance iki string arasındaki Levenshtein mesafesini (bayt bazında) döner
+func EditDistance(s1, s2 string) int {
+	return levenshteinDistance(s1, s2)
+}
+
+// Score seçili algoritmaya göre benzerlik skorunu hesaplar (0-1 arası).
+// Müşteri eşleştirmede adres karşılaştırması için kullanılan skordur.
+func (a SimilarityAlgorithm) Score(s1, s2 string) float64 {
+	return a.score(s1, s2)
+}
+
 // calculateSimilarityScore iki string arasındaki benzerlik skorunu hesaplar (0-1 arası)
 func calculateSimilarityScore(s1, s2 string) float64 {
 	// Normalize strings
@@ -785,6 +2232,110 @@ func calculateSimilarityScore(s1, s2 string) float64 {
 	return (longerLength - float64(editDistance)) / longerLength
 }
 
+// SimilarityAlgorithm metin benzerliği algoritması
+type SimilarityAlgorithm int
+
+const (
+	// SimilarityLevenshtein normalize edilmiş Levenshtein skoru (varsayılan)
+	SimilarityLevenshtein SimilarityAlgorithm = iota
+	// SimilarityJaroWinkler ön ek ve harf yer değiştirmelerine toleranslı Jaro-Winkler skoru
+	SimilarityJaroWinkler
+)
+
+// score seçili algoritmaya göre benzerlik skorunu hesaplar (0-1 arası)
+func (a SimilarityAlgorithm) score(s1, s2 string) float64 {
+	if a == SimilarityJaroWinkler {
+		return calculateJaroWinklerScore(s1, s2)
+	}
+	return calculateSimilarityScore(s1, s2)
+}
+
+// calculateJaroWinklerScore iki string arasındaki Jaro-Winkler skorunu hesaplar (0-1 arası)
+func calculateJaroWinklerScore(s1, s2 string) float64 {
+	// Normalize strings
+	r1 := []rune(strings.ToLower(strings.TrimSpace(s1)))
+	r2 := []rune(strings.ToLower(strings.TrimSpace(s2)))
+
+	if string(r1) == string(r2) {
+		return 1.0
+	}
+
+	if len(r1) == 0 || len(r2) == 0 {
+		return 0.0
+	}
+
+	jaro := jaroSimilarity(r1, r2)
+
+	// Ortak ön ek (en fazla 4 karakter) skoru yükseltir
+	prefix := 0
+	for prefix < len(r1) && prefix < len(r2) && prefix < 4 && r1[prefix] == r2[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// jaroSimilarity calculates the Jaro similarity between two rune slices
+func jaroSimilarity(r1, r2 []rune) float64 {
+	matchDistance := len(r1)
+	if len(r2) > matchDistance {
+		matchDistance = len(r2)
+	}
+	matchDistance = matchDistance/2 - 1
+	if matchDistance < 0 {
+		matchDistance = 0
+	}
+
+	matched1 := make([]bool, len(r1))
+	matched2 := make([]bool, len(r2))
+
+	// Find matching characters within the window
+	matches := 0
+	for i := range r1 {
+		start := i - matchDistance
+		if start < 0 {
+			start = 0
+		}
+		end := i + matchDistance + 1
+		if end > len(r2) {
+			end = len(r2)
+		}
+
+		for j := start; j < end; j++ {
+			if matched2[j] || r1[i] != r2[j] {
+				continue
+			}
+			matched1[i] = true
+			matched2[j] = true
+			matches++
+			break
+		}
+	}
+
+	if matches == 0 {
+		return 0.0
+	}
+
+	// Count transpositions
+	transpositions := 0
+	k := 0
+	for i := range r1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[k] {
+			k++
+		}
+		if r1[i] != r2[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(r1)) + m/float64(len(r2)) + (m-float64(transpositions)/2)/m) / 3
+}
+
 // levenshteinDistance calculates the Levenshtein distance between two strings
 func levenshteinDistance(s1, s2 string) int {
 	if len(s1) == 0 {
@@ -841,6 +2392,35 @@ func min(a, b, c int) int {
 	return c
 }
 
+var (
+	selectTagRe      = regexp.MustCompile(`(?is)<select\b([^>]*)>(.*?)</select>`)
+	elementIDRe      = regexp.MustCompile(`(?i)(?:^|\s)id="([^"]*)"`)
+	optionTagRe      = regexp.MustCompile(`(?is)<option\b[^>]*>`)
+	optionSelectedRe = regexp.MustCompile(`(?i)\sselected\b`)
+	optionValueRe    = regexp.MustCompile(`(?i)\bvalue="([^"]*)"`)
+)
+
+// selectedOptionValue id'si verilen select içinde seçili option'ın değerini döner.
+// Attribute sırası ve selected yazımı (selected, selected="selected") önemli değildir.
+func selectedOptionValue(htmlStr, id string) string {
+	for _, matches := range selectTagRe.FindAllStringSubmatch(htmlStr, -1) {
+		if selectID := elementIDRe.FindStringSubmatch(matches[1]); len(selectID) < 2 || !strings.EqualFold(selectID[1], id) {
+			continue
+		}
+
+		for _, option := range optionTagRe.FindAllString(matches[2], -1) {
+			if !optionSelectedRe.MatchString(option) {
+				continue
+			}
+			if value := optionValueRe.FindStringSubmatch(option); len(value) > 1 {
+				return strings.TrimSpace(value[1])
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
 // parseIntOrZero parses string to int, returns 0 on error
 func parseIntOrZero(s string) int {
 	var result int
@@ -848,149 +2428,124 @@ func parseIntOrZero(s string) int {
 	return result
 }
 
-// CreateCustomerOrGetExisting müşteri oluşturur veya mevcut müşteriyi döner
+// matchScorer client konfigürasyonuna göre skorlayıcı döner
+func (c *Client) matchScorer() matchScorer {
+	return matchScorer{similarity: c.config.Similarity, weights: c.config.MatchWeights}
+}
+
+// CreateCustomerOrGetExisting müşteri oluşturur veya mevcut müşteriyi döner.
+// En iyi adayın skoru WithMatchThreshold ile verilen eşiğin altındaysa ErrNoConfidentMatch döner.
 func (c *Client) CreateCustomerOrGetExisting(customer Customer) (string, error) {
+	result, err := c.CreateCustomerOrGetExistingDetailed(customer)
+	if err != nil && !errors.Is(err, ErrAmbiguousMatch) {
+		return "", err
+	}
+
+	// Belirsiz eşleşmede de en iyi aday döner
+	return result.CustomerID, nil
+}
+
+// CreateCustomerOrGetExistingDetailed müşteri oluşturur veya mevcut müşteriyi skor ve alternatiflerle döner.
+// En iyi skor güven eşiğinin altındaysa sonuç ile birlikte ErrAmbiguousMatch,
+// WithMatchThreshold eşiğinin altındaysa ErrNoConfidentMatch döner.
+func (c *Client) CreateCustomerOrGetExistingDetailed(customer Customer) (*MatchResult, error) {
 	// Önce müşteri oluşturmayı dene
 	customerID, err := c.CreateCustomer(customer)
 	if err == nil {
 		// Başarılı - yeni müşteri oluşturuldu
-		return customerID, nil
+		return &MatchResult{CustomerID: customerID, Created: true, Score: 1.0}, nil
 	}
 
 	// Hata mesajında "zaten kayıtlıdır" kontrolü
-	if strings.Contains(err.Error(), "zaten kayıtlıdır") {
-		// Müşteri zaten var - pagination ile ara
-		var allMatches []RecipientListItem
-		customerNameLower := strings.ToLower(strings.TrimSpace(customer.Name))
-		start := 0
-		length := 200
-		highConfidenceScore := 0.8 // %80 üzeri eşleşme varsa dur
-		
-		for {
-			recipientList, listErr := c.GetRecipientList(start, length)
-			if listErr != nil {
-				return "", fmt.Errorf("müşteri listesi alınamadı: %w", listErr)
+	if !strings.Contains(err.Error(), "zaten kayıtlıdır") {
+		// Başka bir hata oluştu
+		return nil, err
+	}
+
+	scorer := c.matchScorer()
+
+	// Müşteri zaten var - pagination ile ara
+	var candidates []RecipientCandidate
+	customerNameLower := strings.ToLower(strings.TrimSpace(customer.Name))
+	start := 0
+	length := 200
+	highConfidenceScore := 0.8 // %80 üzeri eşleşme varsa dur
+
+	for {
+		recipientList, listErr := c.GetRecipientList(start, length)
+		if listErr != nil {
+			return nil, fmt.Errorf("müşteri listesi alınamadı: %w", listErr)
+		}
+
+		// Bu sayfadaki eşleşmeleri bul
+		for _, recipient := range recipientList.Data {
+			recipientNameLower := strings.ToLower(strings.TrimSpace(recipient.AliciAdi))
+			if recipientNameLower != customerNameLower {
+				continue
 			}
-			
-			// Bu sayfadaki eşleşmeleri bul
-			for _, recipient := range recipientList.Data {
-				recipientNameLower := strings.ToLower(strings.TrimSpace(recipient.AliciAdi))
-				if recipientNameLower == customerNameLower {
-					allMatches = append(allMatches, recipient)
-					
-					// Tek eşleşme varsa hemen kontrol et
-					if len(allMatches) == 1 {
-						// Detay al ve skor hesapla
-						detail, detailErr := c.GetRecipientDetail(recipient.IdAlici)
-						if detailErr == nil {
-							score := 0.0
-							addressScore := calculateSimilarityScore(detail.Address, customer.Address)
-							score += addressScore * 0.5
-							if detail.CityID == customer.CityID {
-								score += 0.3
-							}
-							if detail.DistrictID == customer.DistrictID {
-								score += 0.2
-							}
-							
-							// Yüksek skorlu eşleşme bulundu - dur
-							if score >= highConfidenceScore {
-								return fmt.Sprintf("%d", recipient.IdAlici), nil
-							}
-						}
+			candidate := RecipientCandidate{Recipient: recipient}
+
+			// Tek eşleşme varsa hemen kontrol et
+			if len(candidates) == 0 {
+				// Detay al ve skor hesapla
+				if detail, detailErr := c.GetRecipientDetail(recipient.IdAlici); detailErr == nil {
+					candidate.Detail = detail
+					score := scorer.score(recipient, detail, customer)
+
+					// Yüksek skorlu eşleşme bulundu - dur
+					if score >= highConfidenceScore && score >= c.config.MatchThreshold {
+						return &MatchResult{
+							CustomerID: fmt.Sprintf("%d", recipient.IdAlici),
+							Score:      score,
+						}, nil
 					}
 				}
 			}
-			
-			// Eğer gelen veri sayısı length'ten azsa, tüm veri alındı
-			if len(recipientList.Data) < length {
-				break
-			}
-			
-			// Sonraki sayfa
-			start += length
-		}
-		
-		// Hiç eşleşme bulunamadı
-		if len(allMatches) == 0 {
-			return "", fmt.Errorf("müşteri zaten kayıtlı ancak listede bulunamadı: %s", customer.Name)
-		}
 
-		// Tek eşleşme varsa direkt dön
-		if len(allMatches) == 1 {
-			return fmt.Sprintf("%d", allMatches[0].IdAlici), nil
+			candidates = append(candidates, candidate)
 		}
 
-		// Birden fazla eşleşme var - adres benzerliğine göre sırala
-		type scoredMatch struct {
-			recipient RecipientListItem
-			score     float64
+		// Eğer gelen veri sayısı length'ten azsa, tüm veri alındı
+		if len(recipientList.Data) < length {
+			break
 		}
 
-		var scoredMatches []scoredMatch
-
-		for _, match := range allMatches {
-			// Detaylı bilgi al
-			detail, detailErr := c.GetRecipientDetail(match.IdAlici)
-			if detailErr != nil {
-				// Detay alınamazsa sadece mevcut bilgiyle skor hesapla
-				score := 0.0
-
-				// İl kontrolü
-				if match.IdIl == parseIntOrZero(customer.CityID) {
-					score += 0.3
-				}
+		// Sonraki sayfa
+		start += length
+	}
 
-				// İlçe kontrolü
-				if match.IdIlce == parseIntOrZero(customer.DistrictID) {
-					score += 0.2
-				}
+	// Hiç eşleşme bulunamadı
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("müşteri zaten kayıtlı ancak listede bulunamadı: %s", customer.Name)
+	}
 
-				scoredMatches = append(scoredMatches, scoredMatch{
-					recipient: match,
-					score:     score,
-				})
+	// Birden fazla eşleşme var - detayları al, adres benzerliğine göre sırala
+	if len(candidates) > 1 {
+		for i := range candidates {
+			if candidates[i].Detail != nil {
 				continue
 			}
-
-			// Detaylı skorlama
-			score := 0.0
-
-			// Adres benzerliği (en önemli - %50)
-			addressScore := calculateSimilarityScore(detail.Address, customer.Address)
-			score += addressScore * 0.5
-
-			// İl kontrolü (%30)
-			if detail.CityID == customer.CityID {
-				score += 0.3
-			}
-
-			// İlçe kontrolü (%20)
-			if detail.DistrictID == customer.DistrictID {
-				score += 0.2
+			// Detay alınamazsa sadece mevcut bilgiyle skorlanır
+			if detail, detailErr := c.GetRecipientDetail(candidates[i].Recipient.IdAlici); detailErr == nil {
+				candidates[i].Detail = detail
 			}
-
-			scoredMatches = append(scoredMatches, scoredMatch{
-				recipient: match,
-				score:     score,
-			})
 		}
+	}
 
-		// En yüksek skora sahip olanı bul
-		if len(scoredMatches) > 0 {
-			bestMatch := scoredMatches[0]
-			for _, sm := range scoredMatches[1:] {
-				if sm.score > bestMatch.score {
-					bestMatch = sm
-				}
-			}
-			return fmt.Sprintf("%d", bestMatch.recipient.IdAlici), nil
-		}
+	scored := scorer.match(candidates, customer)
+	result := &MatchResult{
+		CustomerID:   fmt.Sprintf("%d", scored[0].Recipient.IdAlici),
+		Score:        scored[0].Score,
+		Alternatives: scored[1:],
+	}
+
+	if result.Score < c.config.MatchThreshold {
+		return result, fmt.Errorf("%w: en iyi skor %.2f, eşik %.2f", ErrNoConfidentMatch, result.Score, c.config.MatchThreshold)
+	}
 
-		// Hiç skor hesaplanamadıysa ilk eşleşeni dön
-		return fmt.Sprintf("%d", allMatches[0].IdAlici), nil
+	if result.Score < c.config.MatchConfidence {
+		return result, fmt.Errorf("%w: en iyi skor %.2f, eşik %.2f", ErrAmbiguousMatch, result.Score, c.config.MatchConfidence)
 	}
 
-	// Başka bir hata oluştu
-	return "", err
+	return result, nil
 }
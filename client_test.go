@@ -0,0 +1,536 @@
+package nettefatura_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vahaponur/nettefatura"
+	"github.com/vahaponur/nettefatura/nettefaturatest"
+)
+
+// newLoggedInClient sahte sunucuya bağlı ve giriş yapmış client döner
+func newLoggedInClient(t *testing.T, options ...nettefatura.Option) (*nettefaturatest.Server, *nettefatura.Client) {
+	t.Helper()
+
+	srv := nettefaturatest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := srv.NewClient("1", options...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.Login("1111111111", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return srv, client
+}
+
+func TestLogin(t *testing.T) {
+	srv, client := newLoggedInClient(t)
+
+	// Token giriş sayfasından alınır
+	tokenPage := srv.LastRequest("/account/login")
+	if tokenPage == nil || tokenPage.Method != "GET" {
+		t.Fatalf("giriş sayfası GET ile istenmedi: %+v", tokenPage)
+	}
+
+	login := srv.LastRequest("/Account/Login")
+	if login == nil {
+		t.Fatal("giriş isteği gönderilmedi")
+	}
+	if login.Method != "POST" {
+		t.Errorf("giriş metodu = %s, beklenen POST", login.Method)
+	}
+	for field, want := range map[string]string{
+		"VknTckn":                    "1111111111",
+		"Password":                   "secret",
+		"__RequestVerificationToken": nettefaturatest.DefaultToken,
+	} {
+		if got := login.Form.Get(field); got != want {
+			t.Errorf("%s = %q, beklenen %q", field, got, want)
+		}
+	}
+
+	// Oturum cookie'si jar'a yazılır
+	session, err := client.ExportSession()
+	if err != nil {
+		t.Fatalf("ExportSession: %v", err)
+	}
+	if !strings.Contains(string(session), "test-session") {
+		t.Errorf("oturum cookie'si kaydedilmedi: %s", session)
+	}
+
+	ok, err := client.IsAuthenticated()
+	if err != nil || !ok {
+		t.Errorf("IsAuthenticated = %v, %v; beklenen true", ok, err)
+	}
+}
+
+func TestLoginInvalidCredentials(t *testing.T) {
+	srv := nettefaturatest.NewServer()
+	defer srv.Close()
+
+	client, err := srv.NewClient("1")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	err = client.Login("1111111111", "")
+	if !errors.Is(err, nettefatura.ErrInvalidCredentials) {
+		t.Fatalf("Login hatası = %v, beklenen ErrInvalidCredentials", err)
+	}
+	if !strings.Contains(err.Error(), "şifre hatalı") {
+		t.Errorf("portal hata mesajı hataya eklenmedi: %v", err)
+	}
+}
+
+func TestCreateCustomer(t *testing.T) {
+	srv, client := newLoggedInClient(t)
+
+	cityID := nettefatura.GetCityID("İstanbul")
+	districtID := nettefatura.GetDistrictID(cityID, "Kadıköy")
+	if cityID == "-1" || districtID == -1 {
+		t.Fatalf("il/ilçe bulunamadı: %s %d", cityID, districtID)
+	}
+
+	customerID, err := client.CreateCustomer(nettefatura.Customer{
+		Name:       "Test Ltd. Şti.",
+		TaxNumber:  "1234567890",
+		Email:      "muhasebe@example.com",
+		CityID:     cityID,
+		DistrictID: fmt.Sprintf("%d", districtID),
+	})
+	if err != nil {
+		t.Fatalf("CreateCustomer: %v", err)
+	}
+	if customerID != "1001" {
+		t.Errorf("müşteri ID = %s, beklenen 1001", customerID)
+	}
+
+	form := srv.LastRequest("/Recipient/Create").Form
+	for field, want := range map[string]string{
+		"AliciAdi":                   "Test Ltd. Şti.",
+		"Vnktckn":                    "1234567890",
+		"AliciTipi":                  "2", // 10 haneli VKN kurumsal
+		"FaturaGonderimSekli":        "1",
+		"IdIl":                       cityID,
+		"IlAdi":                      "İstanbul",
+		"IdFirma":                    "1",
+		"__RequestVerificationToken": nettefaturatest.DefaultToken,
+	} {
+		if got := form.Get(field); got != want {
+			t.Errorf("%s = %q, beklenen %q", field, got, want)
+		}
+	}
+}
+
+func TestCreateInvoice(t *testing.T) {
+	srv, client := newLoggedInClient(t)
+
+	invoiceNo, err := client.CreateInvoice(nettefatura.Invoice{
+		CustomerID:    "1001",
+		RecipientType: nettefatura.RecipientTypeEArchive,
+		SendingType:   nettefatura.SendingTypePaper,
+		InvoiceTime:   "10:30",
+		Notes:         []string{"", "Teşekkürler"},
+		Products: []nettefatura.Product{
+			{Name: "Hizmet", Quantity: 2, Price: 50, VATRate: 20},
+			{Name: "Kitap", Quantity: 1, Price: 100, VATRate: 10},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateInvoice: %v", err)
+	}
+	if invoiceNo != "TST2024000000001" {
+		t.Errorf("fatura no = %s", invoiceNo)
+	}
+
+	form := srv.LastRequest("/Invoice/Create").Form
+	if got := form.Get("__RequestVerificationToken"); got != nettefaturatest.DefaultToken {
+		t.Errorf("token = %q", got)
+	}
+
+	var payload struct {
+		IdAlici                  string
+		RecipientType            string
+		CompanyId                string
+		InvoiceTime              string
+		Notes                    []string
+		Products                 []map[string]interface{}
+		TotalLineExtensionAmount float64
+		TotalVATAmount           float64
+		TotalPayableAmount       float64
+	}
+	if err := json.Unmarshal([]byte(form.Get("jsonData")), &payload); err != nil {
+		t.Fatalf("jsonData parse: %v", err)
+	}
+
+	if payload.IdAlici != "1001" || payload.RecipientType != "2" || payload.CompanyId != "1" {
+		t.Errorf("alıcı alanları hatalı: %+v", payload)
+	}
+	if payload.InvoiceTime != "10:30:00" {
+		t.Errorf("InvoiceTime = %q, beklenen 10:30:00", payload.InvoiceTime)
+	}
+	if len(payload.Notes) != 1 || payload.Notes[0] != "Teşekkürler" {
+		t.Errorf("notlar = %q", payload.Notes)
+	}
+	if len(payload.Products) != 2 {
+		t.Fatalf("ürün satırı sayısı = %d", len(payload.Products))
+	}
+	if payload.TotalLineExtensionAmount != 200 || payload.TotalVATAmount != 30 || payload.TotalPayableAmount != 230 {
+		t.Errorf("toplamlar = %v / %v / %v, beklenen 200 / 30 / 230",
+			payload.TotalLineExtensionAmount, payload.TotalVATAmount, payload.TotalPayableAmount)
+	}
+}
+
+func TestCreateInvoiceRawSharesPayload(t *testing.T) {
+	srv, client := newLoggedInClient(t)
+
+	invoice := nettefatura.Invoice{
+		CustomerID:    "1001",
+		RecipientType: nettefatura.RecipientTypeEArchive,
+		SendingType:   nettefatura.SendingTypePaper,
+		InvoiceTime:   "09:00:00",
+		Products:      []nettefatura.Product{{Name: "Hizmet", Quantity: 1, Price: 100, VATRate: 20}},
+	}
+
+	if _, err := client.CreateInvoice(invoice); err != nil {
+		t.Fatalf("CreateInvoice: %v", err)
+	}
+	parsed := srv.LastRequest("/Invoice/Create").Form.Get("jsonData")
+
+	raw, err := client.CreateInvoiceRaw(invoice)
+	if err != nil {
+		t.Fatalf("CreateInvoiceRaw: %v", err)
+	}
+	if string(raw) != `"TST2024000000001"` {
+		t.Errorf("ham yanıt = %s", raw)
+	}
+	if got := srv.LastRequest("/Invoice/Create").Form.Get("jsonData"); got != parsed {
+		t.Errorf("CreateInvoiceRaw farklı payload gönderdi:\n%s\n%s", got, parsed)
+	}
+}
+
+func TestCreateInvoiceRequiresCustomerID(t *testing.T) {
+	srv, client := newLoggedInClient(t)
+
+	for _, id := range []string{"", "  ", "abc"} {
+		_, err := client.CreateInvoice(nettefatura.Invoice{
+			CustomerID: id,
+			Products:   []nettefatura.Product{{Name: "Hizmet", Quantity: 1, Price: 100, VATRate: 20}},
+		})
+		if err == nil {
+			t.Errorf("CustomerID %q kabul edildi", id)
+		}
+	}
+	if srv.LastRequest("/Invoice/Create") != nil {
+		t.Error("geçersiz fatura portala gönderildi")
+	}
+}
+
+func TestGetAllRecipientsPagination(t *testing.T) {
+	srv, client := newLoggedInClient(t)
+
+	for i := 1; i <= 450; i++ {
+		srv.Recipients = append(srv.Recipients, nettefatura.RecipientListItem{
+			IdAlici:  i,
+			AliciAdi: fmt.Sprintf("Müşteri %d", i),
+		})
+	}
+
+	var ids []int
+	err := client.GetAllRecipients(func(r nettefatura.RecipientListItem) error {
+		ids = append(ids, r.IdAlici)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetAllRecipients: %v", err)
+	}
+	if len(ids) != 450 || ids[0] != 1 || ids[449] != 450 {
+		t.Fatalf("%d müşteri alındı, beklenen 450 (sırayla)", len(ids))
+	}
+
+	var starts []string
+	for _, req := range srv.Requests() {
+		if req.Path == "/Recipient/GetRecipientList" {
+			starts = append(starts, req.Form.Get("start"))
+			if got := req.Form.Get("length"); got != "200" {
+				t.Errorf("length = %s, beklenen 200", got)
+			}
+		}
+	}
+	if strings.Join(starts, ",") != "0,200,400" {
+		t.Errorf("sayfa başlangıçları = %v, beklenen [0 200 400]", starts)
+	}
+}
+
+func TestGetRecipientListOrderAndFilter(t *testing.T) {
+	srv, client := newLoggedInClient(t)
+
+	srv.Recipients = []nettefatura.RecipientListItem{
+		{IdAlici: 1, AliciAdi: "Beta", AliciTipi: 2},
+		{IdAlici: 2, AliciAdi: "Alfa", AliciTipi: 1},
+		{IdAlici: 3, AliciAdi: "Gama", AliciTipi: 2},
+	}
+
+	list, err := client.GetRecipientListFiltered(0, 10,
+		nettefatura.RecipientFilter{Type: nettefatura.CustomerTypeCorporate},
+		nettefatura.RecipientOrder{Column: nettefatura.RecipientSortByName, Descending: true},
+	)
+	if err != nil {
+		t.Fatalf("GetRecipientListFiltered: %v", err)
+	}
+	if len(list.Data) != 2 || list.Data[0].AliciAdi != "Gama" || list.Data[1].AliciAdi != "Beta" {
+		t.Errorf("sonuç = %+v", list.Data)
+	}
+
+	form := srv.LastRequest("/Recipient/GetRecipientList").Form
+	for field, want := range map[string]string{
+		"AliciTipi":             "2",
+		"RecipientState":        "1",
+		"order[0][column]":      "2",
+		"order[0][dir]":         "desc",
+		"columns[2][orderable]": "true",
+		"columns[3][orderable]": "false",
+	} {
+		if got := form.Get(field); got != want {
+			t.Errorf("%s = %q, beklenen %q", field, got, want)
+		}
+	}
+}
+
+func TestCreateCustomerOrGetExistingBelowConfidence(t *testing.T) {
+	srv, client := newLoggedInClient(t)
+	srv.RecipientCreateResponse = `{"error":"Bu VKN/TCKN ile alıcı zaten kayıtlıdır."}`
+	srv.Recipients = []nettefatura.RecipientListItem{
+		{IdAlici: 1, AliciAdi: "Ahmet Yılmaz", Vnktckn: "11111111111", IdIl: 6, IlAdi: "Ankara", SokakAdi: "Kızılay Cad."},
+		{IdAlici: 2, AliciAdi: "Ahmet Yılmaz", Vnktckn: "11111111111", IdIl: 35, IlAdi: "İzmir", SokakAdi: "Atatürk Cad. No 5"},
+	}
+
+	cityID := nettefatura.GetCityID("İstanbul")
+	customer := nettefatura.Customer{
+		Name:       "Ahmet Yılmaz",
+		TaxNumber:  "11111111111",
+		Email:      "ahmet@example.com",
+		Address:    "Atatürk Caddesi No 5",
+		CityID:     cityID,
+		DistrictID: fmt.Sprintf("%d", nettefatura.GetDistrictID(cityID, "Kadıköy")),
+	}
+
+	// Güven eşiği (0.5) yalnızca detaylı sonuçta uyarı olarak döner
+	result, err := client.CreateCustomerOrGetExistingDetailed(customer)
+	if !errors.Is(err, nettefatura.ErrAmbiguousMatch) {
+		t.Fatalf("hata = %v, beklenen ErrAmbiguousMatch", err)
+	}
+	if result.CustomerID != "2" || result.Score >= 0.5 {
+		t.Errorf("sonuç = %s (%.2f), beklenen 2 (< 0.5)", result.CustomerID, result.Score)
+	}
+
+	// Seçilen müşteri eşikten etkilenmez
+	customerID, err := client.CreateCustomerOrGetExisting(customer)
+	if err != nil {
+		t.Fatalf("CreateCustomerOrGetExisting: %v", err)
+	}
+	if customerID != result.CustomerID {
+		t.Errorf("müşteri ID = %s, beklenen %s", customerID, result.CustomerID)
+	}
+}
+
+func TestCaptureRedactsAttachmentToken(t *testing.T) {
+	var dumps []string
+	srv, client := newLoggedInClient(t, nettefatura.WithCapture(func(reqDump, respDump []byte) {
+		dumps = append(dumps, string(reqDump))
+	}))
+
+	err := client.AttachDocument("5001", "sozlesme.pdf", []byte("%PDF-1.4\n%test\n"))
+	if err != nil {
+		t.Fatalf("AttachDocument: %v", err)
+	}
+	if len(srv.Attachments) != 1 {
+		t.Fatalf("%d ek belge yüklendi, beklenen 1", len(srv.Attachments))
+	}
+
+	for _, dump := range dumps {
+		if strings.Contains(dump, nettefaturatest.DefaultToken) {
+			t.Errorf("CSRF token dökümde maskelenmedi:\n%s", dump)
+		}
+	}
+}
+
+func TestCreateInvoicesPartialFailure(t *testing.T) {
+	srv, client := newLoggedInClient(t, nettefatura.WithBatchConcurrency(2))
+
+	product := []nettefatura.Product{{Name: "Hizmet", Quantity: 1, Price: 100, VATRate: 20}}
+	invoices := []nettefatura.Invoice{
+		{CustomerID: "1001", RecipientType: nettefatura.RecipientTypeEArchive, SendingType: nettefatura.SendingTypePaper, Products: product},
+		{CustomerID: "", Products: product},
+		{CustomerID: "1002", RecipientType: nettefatura.RecipientTypeEArchive, SendingType: nettefatura.SendingTypePaper, Products: product},
+	}
+
+	results, err := client.CreateInvoices(invoices)
+	if err == nil {
+		t.Fatal("hatalı fatura için hata dönmedi")
+	}
+	if len(results) != 3 {
+		t.Fatalf("%d sonuç, beklenen 3", len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("%d. sonucun sırası %d", i, result.Index)
+		}
+		if failed := result.Err != nil; failed != (i == 1) {
+			t.Errorf("%d. fatura: %v", i, result.Err)
+		}
+	}
+
+	// Başarısız fatura tekrar denenmez
+	sent := 0
+	for _, req := range srv.Requests() {
+		if req.Path == "/Invoice/Create" {
+			sent++
+		}
+	}
+	if sent != 2 {
+		t.Errorf("%d fatura gönderildi, beklenen 2", sent)
+	}
+}
+
+func TestCreateInvoiceRecipientLookup(t *testing.T) {
+	product := []nettefatura.Product{{Name: "Hizmet", Quantity: 1, Price: 100, VATRate: 20}}
+
+	countRequests := func(srv *nettefaturatest.Server, path string) int {
+		n := 0
+		for _, req := range srv.Requests() {
+			if req.Path == path {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Run("alanlar verilmişse sorgu yapılmaz", func(t *testing.T) {
+		srv, client := newLoggedInClient(t)
+		_, err := client.CreateInvoice(nettefatura.Invoice{
+			CustomerID:       "1001",
+			ReceiverInboxTag: "urn:mail:defaultpk@example.com",
+			SendingType:      nettefatura.SendingTypeElectronic,
+			Products:         product,
+		})
+		if err != nil {
+			t.Fatalf("CreateInvoice: %v", err)
+		}
+		if n := countRequests(srv, "/Recipient/Detail") + countRequests(srv, "/Recipient/CheckGibUser"); n != 0 {
+			t.Errorf("%d gereksiz sorgu yapıldı", n)
+		}
+		if !strings.Contains(srv.LastRequest("/Invoice/Create").Form.Get("jsonData"), `"RecipientType":"1"`) {
+			t.Error("etiket verilen fatura e-fatura olarak gönderilmedi")
+		}
+	})
+
+	recipient := nettefatura.RecipientListItem{
+		IdAlici: 1001, AliciAdi: "Test Ltd. Şti.", Vnktckn: "1234567890", Email: "muhasebe@example.com", FaturaGonderimSekli: 1,
+	}
+	payload := func(t *testing.T, srv *nettefaturatest.Server) string {
+		t.Helper()
+		req := srv.LastRequest("/Invoice/Create")
+		if req == nil {
+			t.Fatal("fatura gönderilmedi")
+		}
+		return req.Form.Get("jsonData")
+	}
+
+	t.Run("alıcı kaydı alınamazsa e-arşiv varsayılır", func(t *testing.T) {
+		srv, client := newLoggedInClient(t)
+		if _, err := client.CreateInvoice(nettefatura.Invoice{CustomerID: "1001", Products: product}); err != nil {
+			t.Fatalf("CreateInvoice: %v", err)
+		}
+		if !strings.Contains(payload(t, srv), `"RecipientType":"2"`) {
+			t.Error("fatura e-arşiv olarak gönderilmedi")
+		}
+	})
+
+	t.Run("mükellef e-fatura olarak gönderilir", func(t *testing.T) {
+		srv, client := newLoggedInClient(t)
+		srv.Recipients = []nettefatura.RecipientListItem{recipient}
+		srv.EInvoiceUsers = map[string]nettefatura.MukellefInfo{
+			"1234567890": {VknTckn: "1234567890", Aliases: []string{"urn:mail:defaultpk@example.com"}},
+		}
+		if _, err := client.CreateInvoice(nettefatura.Invoice{CustomerID: "1001", Products: product}); err != nil {
+			t.Fatalf("CreateInvoice: %v", err)
+		}
+		body := payload(t, srv)
+		if !strings.Contains(body, `"RecipientType":"1"`) || !strings.Contains(body, "urn:mail:defaultpk@example.com") {
+			t.Errorf("mükellef e-fatura olarak gönderilmedi: %s", body)
+		}
+	})
+
+	t.Run("mükellef sorgusu başarısızsa e-arşiv varsayılır", func(t *testing.T) {
+		srv, client := newLoggedInClient(t)
+		srv.Recipients = []nettefatura.RecipientListItem{recipient}
+		srv.CheckGibUserStatus = 500
+
+		// Sorgu hatası doğrudan çağrıda tipli hata olarak döner
+		if _, _, err := client.CheckEInvoiceUser("1234567890"); !errors.Is(err, nettefatura.ErrEInvoiceCheckFailed) {
+			t.Errorf("CheckEInvoiceUser hatası = %v, beklenen ErrEInvoiceCheckFailed", err)
+		}
+
+		if _, err := client.CreateInvoice(nettefatura.Invoice{CustomerID: "1001", Products: product}); err != nil {
+			t.Fatalf("CreateInvoice: %v", err)
+		}
+		if countRequests(srv, "/Recipient/CheckGibUser") != 2 {
+			t.Error("mükellef sorgusu yapılmadı")
+		}
+		if !strings.Contains(payload(t, srv), `"RecipientType":"2"`) {
+			t.Error("fatura e-arşiv olarak gönderilmedi")
+		}
+	})
+}
+
+func TestLogoutConcurrentWithRequests(t *testing.T) {
+	_, client := newLoggedInClient(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				client.GetRecipientList(0, 10)
+			}
+		}()
+	}
+	if err := client.Logout(); err != nil {
+		t.Errorf("Logout: %v", err)
+	}
+	wg.Wait()
+
+	session, err := client.ExportSession()
+	if err != nil {
+		t.Fatalf("ExportSession: %v", err)
+	}
+	if strings.Contains(string(session), "test-session") {
+		t.Errorf("oturum cookie'si silinmedi: %s", session)
+	}
+}
+
+func TestGetCompaniesSessionExpired(t *testing.T) {
+	// Oturum kapalıyken portal ana sayfa yerine giriş formunu döner
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<form action="/Account/Login"><input name="VknTckn" /><input type="password" name="Password" /></form>`)
+	}))
+	defer srv.Close()
+
+	client, err := nettefatura.NewClient("1", nettefatura.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.GetCompanies(); !errors.Is(err, nettefatura.ErrSessionExpired) {
+		t.Errorf("hata = %v, beklenen ErrSessionExpired", err)
+	}
+}
@@ -0,0 +1,83 @@
+package nettefatura
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Company kullanıcının adına işlem yapabildiği firma
+type Company struct {
+	ID        string // Config.CompanyID / SetCompanyID değeri
+	Name      string
+	TaxNumber string // VKN/TCKN, seçenek metninde varsa
+}
+
+var (
+	companySelectRe = regexp.MustCompile(`(?is)<select[^>]*\b(?:id|name)="(?:CompanyId|IdFirma)"[^>]*>(.*?)</select>`)
+	companyOptionRe = regexp.MustCompile(`(?is)<option\b[^>]*\bvalue="([^"]*)"[^>]*>(.*?)</option>`)
+	companyTaxNoRe  = regexp.MustCompile(`^(.*?)\s*[\(\-]\s*(\d{10,11})\s*\)?$`)
+)
+
+// GetCompanies giriş yapılmış kullanıcının işlem yapabildiği firmaları
+// giriş sonrası ana sayfadaki firma seçiminden okur
+func (c *Client) GetCompanies() ([]Company, error) {
+	req, err := c.newRequest("GET", c.config.BaseURL+"/", nil)
+	if err != nil {
+		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("firma listesi isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	// Oturum kapalıysa giriş sayfasına yönlendirilir
+	if isLoginForm(string(body)) {
+		return nil, fmt.Errorf("%w: önce Login çağrılmalı", ErrSessionExpired)
+	}
+
+	companies := parseCompanies(string(body))
+	if len(companies) == 0 {
+		return nil, fmt.Errorf("firma listesi bulunamadı")
+	}
+
+	return companies, nil
+}
+
+// parseCompanies firma seçim kutusundaki seçenekleri parse eder
+func parseCompanies(htmlStr string) []Company {
+	matches := companySelectRe.FindStringSubmatch(htmlStr)
+	if len(matches) < 2 {
+		return nil
+	}
+
+	var companies []Company
+	for _, option := range companyOptionRe.FindAllStringSubmatch(matches[1], -1) {
+		id := strings.TrimSpace(option[1])
+		// "Seçiniz" gibi boş seçenekler atlanır
+		if id == "" || id == "0" {
+			continue
+		}
+
+		company := Company{
+			ID:   id,
+			Name: strings.TrimSpace(html.UnescapeString(option[2])),
+		}
+		// "Firma Adı (1234567890)" biçiminde VKN/TCKN ayrılır
+		if parts := companyTaxNoRe.FindStringSubmatch(company.Name); len(parts) > 2 {
+			company.Name = strings.TrimSpace(parts[1])
+			company.TaxNumber = parts[2]
+		}
+		companies = append(companies, company)
+	}
+
+	return companies
+}
@@ -0,0 +1,51 @@
+package nettefatura
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+//go:embed assets/mensei-data.json
+var menseiDataJSON []byte
+
+type MenseiData struct {
+	Countries []Country `json:"countries"`
+}
+
+type Country struct {
+	ID   int    `json:"id"`
+	Code string `json:"code"` // ISO 3166-1 alpha-2
+	Name string `json:"name"`
+}
+
+var menseiData *MenseiData
+
+func init() {
+	menseiData = &MenseiData{}
+	if err := json.Unmarshal(menseiDataJSON, menseiData); err != nil {
+		panic("failed to load mensei data: " + err.Error())
+	}
+}
+
+// GetMenseiID ülke adı veya ISO kodundan menşei ID'sini bulur
+func GetMenseiID(country string) int {
+	normalized := normalizeString(country)
+
+	for _, c := range menseiData.Countries {
+		if normalizeString(c.Name) == normalized || normalizeString(c.Code) == normalized {
+			return c.ID
+		}
+	}
+
+	return -1
+}
+
+// GetMenseiName menşei ID'sinden ülke adını bulur
+func GetMenseiName(menseiID int) string {
+	for _, c := range menseiData.Countries {
+		if c.ID == menseiID {
+			return c.Name
+		}
+	}
+	return "-1"
+}
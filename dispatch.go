@@ -0,0 +1,203 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Dispatch e-İrsaliye bilgileri
+type Dispatch struct {
+	CustomerID   string
+	Date         time.Time // İrsaliye tarihi, boşsa gönderim anı
+	ShipmentDate time.Time // Fiili sevk tarihi, boşsa irsaliye tarihi
+	Lines        []DispatchLine
+	Notes        []string
+
+	VehiclePlate string // Araç plakası (örn. "34ABC123")
+
+	DriverName      string
+	DriverSurname   string
+	DriverTaxNumber string // Şoför TC kimlik no
+
+	// Taşıyıcı firma, boş bırakılırsa gönderen firmanın kendi aracıyla taşındığı varsayılır
+	CarrierName      string
+	CarrierTaxNumber string
+}
+
+// DispatchLine irsaliye satırı
+type DispatchLine struct {
+	ProductID string // Katalog ürün ID (opsiyonel)
+	Name      string
+	Quantity  float64
+	Price     float64 // KDV hariç birim fiyat (opsiyonel)
+}
+
+// DispatchReference faturaya bağlanacak irsaliye
+type DispatchReference struct {
+	Number string    `json:"number"` // İrsaliye numarası (CreateDispatch dönüşü)
+	Date   time.Time `json:"date"`   // İrsaliye tarihi
+}
+
+// validate irsaliyeyi portala göndermeden önce kontrol eder
+func (d Dispatch) validate() error {
+	if d.CustomerID == "" {
+		return fmt.Errorf("müşteri ID gerekli")
+	}
+	if len(d.Lines) == 0 {
+		return fmt.Errorf("en az bir irsaliye satırı gerekli")
+	}
+	for idx, line := range d.Lines {
+		if strings.TrimSpace(line.Name) == "" {
+			return fmt.Errorf("%d. satır: ürün adı zorunludur", idx+1)
+		}
+		if line.Quantity <= 0 {
+			return fmt.Errorf("%d. satır (%s): miktar pozitif olmalıdır", idx+1, line.Name)
+		}
+		if line.Price < 0 {
+			return fmt.Errorf("%d. satır (%s): fiyat negatif olamaz", idx+1, line.Name)
+		}
+	}
+
+	if strings.TrimSpace(d.VehiclePlate) == "" {
+		return fmt.Errorf("araç plakası zorunludur")
+	}
+	if strings.TrimSpace(d.DriverName) == "" || strings.TrimSpace(d.DriverSurname) == "" {
+		return fmt.Errorf("şoför adı ve soyadı zorunludur")
+	}
+	if !isDigits(d.DriverTaxNumber, 11) {
+		return fmt.Errorf("şoför TC kimlik no 11 haneli olmalıdır: %q", d.DriverTaxNumber)
+	}
+	if d.CarrierTaxNumber != "" && !isDigits(d.CarrierTaxNumber, 10) && !isDigits(d.CarrierTaxNumber, 11) {
+		return fmt.Errorf("taşıyıcı VKN/TCKN 10 veya 11 haneli olmalıdır: %q", d.CarrierTaxNumber)
+	}
+	if d.CarrierTaxNumber != "" && strings.TrimSpace(d.CarrierName) == "" {
+		return fmt.Errorf("taşıyıcı VKN/TCKN verildiğinde taşıyıcı unvanı zorunludur")
+	}
+
+	return nil
+}
+
+// isDigits s'nin tam olarak n haneli bir sayı olup olmadığını kontrol eder
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePlate plakayı boşluksuz büyük harfe çevirir
+func normalizePlate(plate string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(plate), ""))
+}
+
+// CreateDispatch e-İrsaliye oluşturur ve irsaliye numarasını döner.
+// Dönen numara Invoice.DispatchList ile faturaya bağlanabilir.
+func (c *Client) CreateDispatch(dispatch Dispatch) (string, error) {
+	// Validasyon
+	if err := dispatch.validate(); err != nil {
+		return "", err
+	}
+
+	// Tarihler
+	if dispatch.Date.IsZero() {
+		dispatch.Date = c.now()
+	}
+	if dispatch.ShipmentDate.IsZero() {
+		dispatch.ShipmentDate = dispatch.Date
+	}
+
+	notes, err := normalizeNotes(dispatch.Notes, c.config.MaxNoteLength)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]map[string]interface{}, 0, len(dispatch.Lines))
+	for _, line := range dispatch.Lines {
+		var productID interface{}
+		if line.ProductID != "" {
+			productID = parseIntOrZero(line.ProductID)
+		}
+		lines = append(lines, map[string]interface{}{
+			"ProductId":       productID,
+			"ProductName":     line.Name,
+			"Quantity":        line.Quantity,
+			"UnitPrice":       c.round(NewMoney(line.Price)).Float64(),
+			"MeasureUnitId":   c.config.MeasureUnit,
+			"DeliveredAmount": line.Quantity,
+		})
+	}
+
+	// Taşıyıcı verilmemişse gönderen firmanın kendisi taşır
+	var carrier interface{}
+	if dispatch.CarrierTaxNumber != "" {
+		carrier = map[string]string{
+			"VknTckn": dispatch.CarrierTaxNumber,
+			"Unvan":   strings.TrimSpace(dispatch.CarrierName),
+		}
+	}
+
+	dispatchData := map[string]interface{}{
+		"ETTN":           "",
+		"DespatchId":     "0",
+		"DespatchNumber": "",
+		"CompanyId":      c.companyID(),
+		"ScenarioType":   "0",
+		"DespatchType":   "1", // Sevk irsaliyesi
+		"DespatchDate":   dispatch.Date.Format("02-01-2006"),
+		"DespatchTime":   dispatch.Date.Format("15:04:05"),
+		"ShipmentDate":   dispatch.ShipmentDate.Format("02-01-2006"),
+		"ShipmentTime":   dispatch.ShipmentDate.Format("15:04:05"),
+		"IdAlici":        dispatch.CustomerID,
+		"Products":       lines,
+		"Notes":          notes,
+		"Carrier":        carrier,
+		"VehiclePlate":   normalizePlate(dispatch.VehiclePlate),
+		"DriverList": []map[string]string{{
+			"Name":    strings.TrimSpace(dispatch.DriverName),
+			"Surname": strings.TrimSpace(dispatch.DriverSurname),
+			"Tckn":    dispatch.DriverTaxNumber,
+		}},
+	}
+
+	jsonData, err := json.Marshal(dispatchData)
+	if err != nil {
+		return "", fmt.Errorf("JSON marshal hatası: %w", err)
+	}
+
+	form := url.Values{
+		"jsonData": {string(jsonData)},
+	}
+
+	body, err := c.postWithToken("/Despatch/CreateQuick", "/Despatch/Create", form, "irsaliye oluşturma")
+	if err != nil {
+		return "", err
+	}
+
+	// Başarılı response irsaliye numarasını string olarak döner
+	dispatchNo := strings.Trim(string(body), `"`)
+	if dispatchNo == "" || strings.Contains(dispatchNo, "error") {
+		return "", fmt.Errorf("irsaliye oluşturulamadı: %s", string(body))
+	}
+
+	return dispatchNo, nil
+}
+
+// dispatchList faturadaki irsaliye referanslarını portal formatına çevirir
+func dispatchList(refs []DispatchReference) []interface{} {
+	list := make([]interface{}, 0, len(refs))
+	for _, ref := range refs {
+		list = append(list, map[string]string{
+			"DespatchNumber": ref.Number,
+			"DespatchDate":   ref.Date.Format("02-01-2006"),
+		})
+	}
+	return list
+}
@@ -0,0 +1,102 @@
+package nettefatura
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CSVColumn WriteInvoicesCSV çıktısındaki kolon
+type CSVColumn string
+
+const (
+	CSVDate      CSVColumn = "date"      // Fatura tarihi (2006-01-02)
+	CSVNumber    CSVColumn = "number"    // Fatura numarası
+	CSVETTN      CSVColumn = "ettn"      // ETTN
+	CSVRecipient CSVColumn = "recipient" // Alıcı adı, yoksa alıcı ID
+	CSVNet       CSVColumn = "net"       // KDV hariç toplam (matrah)
+	CSVVAT       CSVColumn = "vat"       // KDV toplamı
+	CSVGross     CSVColumn = "gross"     // Ödenecek tutar
+)
+
+// csvHeaders kolonların başlık satırındaki adları
+var csvHeaders = map[CSVColumn]string{
+	CSVDate:      "Tarih",
+	CSVNumber:    "Fatura No",
+	CSVETTN:      "ETTN",
+	CSVRecipient: "Alıcı",
+	CSVNet:       "Matrah",
+	CSVVAT:       "KDV",
+	CSVGross:     "Toplam",
+}
+
+// DefaultCSVColumns WriteInvoicesCSV'nin kolon verilmediğinde kullandığı kolonları döner
+func DefaultCSVColumns() []CSVColumn {
+	return []CSVColumn{CSVDate, CSVNumber, CSVETTN, CSVRecipient, CSVNet, CSVVAT, CSVGross}
+}
+
+// WriteInvoicesCSV faturaları muhasebe programlarına aktarım için başlık satırıyla CSV olarak yazar.
+// Kolonlar verilmezse DefaultCSVColumns kullanılır. Tutarlar iki ondalıklı ve nokta ayraçlıdır.
+func WriteInvoicesCSV(w io.Writer, invoices []InvoiceDetail, columns ...CSVColumn) error {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns()
+	}
+
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		name, ok := csvHeaders[column]
+		if !ok {
+			return fmt.Errorf("bilinmeyen CSV kolonu: %q", column)
+		}
+		header = append(header, name)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("CSV yazılamadı: %w", err)
+	}
+
+	for _, invoice := range invoices {
+		record := make([]string, 0, len(columns))
+		for _, column := range columns {
+			record = append(record, invoice.csvValue(column))
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("CSV yazılamadı: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("CSV yazılamadı: %w", err)
+	}
+	return nil
+}
+
+// csvValue faturanın kolondaki değerini döner
+func (d InvoiceDetail) csvValue(column CSVColumn) string {
+	switch column {
+	case CSVDate:
+		if date := parseStatementDate(d.InvoiceDate); !date.IsZero() {
+			return date.Format("2006-01-02")
+		}
+		return d.InvoiceDate
+	case CSVNumber:
+		return d.InvoiceNumber
+	case CSVETTN:
+		return d.ETTN
+	case CSVRecipient:
+		if d.RecipientName != "" {
+			return d.RecipientName
+		}
+		return idString(d.RecipientId)
+	case CSVNet:
+		return NewMoney(d.TotalLineExtensionAmount).StringFixed(2)
+	case CSVVAT:
+		return NewMoney(d.TotalVATAmount).StringFixed(2)
+	case CSVGross:
+		return NewMoney(d.TotalPayableAmount).StringFixed(2)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,80 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrEInvoiceCheckFailed mükellef sorgusu portaldan geçerli bir yanıt alamadı
+// (hata status'u veya JSON yerine HTML sayfa). Alıcının mükellefiyeti bilinmiyor demektir.
+var ErrEInvoiceCheckFailed = errors.New("mükellef sorgusu başarısız")
+
+// MukellefInfo GİB mükellef sorgulama sonucu
+type MukellefInfo struct {
+	VknTckn        string   `json:"VknTckn"`
+	Unvan          string   `json:"Unvan"`
+	IsEInvoiceUser bool     `json:"IsEInvoiceUser"`
+	Aliases        []string `json:"Aliases"` // Posta kutusu etiketleri (urn:mail:...)
+	RegisterTime   string   `json:"FirstCreationTime"`
+}
+
+// DefaultAlias ilk posta kutusu etiketini döner, yoksa boş string
+func (m *MukellefInfo) DefaultAlias() string {
+	if m == nil || len(m.Aliases) == 0 {
+		return ""
+	}
+	return m.Aliases[0]
+}
+
+// CheckEInvoiceUser vergi/TC kimlik numarasının GİB e-fatura sistemine kayıtlı olup olmadığını sorgular.
+// Kayıtlı değilse alıcıya e-arşiv fatura kesilmelidir.
+func (c *Client) CheckEInvoiceUser(taxNumber string) (bool, *MukellefInfo, error) {
+	taxNumber = strings.TrimSpace(taxNumber)
+	if taxNumber == "" {
+		return false, nil, fmt.Errorf("vergi/TC kimlik no zorunludur")
+	}
+
+	endpoint := fmt.Sprintf("%s/Recipient/CheckGibUser?vknTckn=%s", c.config.BaseURL, url.QueryEscape(taxNumber))
+
+	req, err := c.newRequest("GET", endpoint, nil)
+	if err != nil {
+		return false, nil, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, nil, fmt.Errorf("mükellef sorgulama isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return false, nil, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, nil, fmt.Errorf("%w, status: %d", ErrEInvoiceCheckFailed, resp.StatusCode)
+	}
+
+	var info MukellefInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return false, nil, fmt.Errorf("%w: JSON parse hatası: %v", ErrEInvoiceCheckFailed, err)
+	}
+
+	if info.VknTckn == "" {
+		info.VknTckn = taxNumber
+	}
+
+	// Etiketi olan mükellef e-fatura kullanıcısıdır
+	if len(info.Aliases) > 0 {
+		info.IsEInvoiceUser = true
+	}
+
+	return info.IsEInvoiceUser, &info, nil
+}
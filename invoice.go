@@ -0,0 +1,918 @@
+package nettefatura
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrInvoiceNotFound fatura portalda bulunamadı
+	ErrInvoiceNotFound = errors.New("fatura bulunamadı")
+	// ErrInvoiceNotApproved fatura henüz onaylanmadığı için işlem yapılamıyor
+	ErrInvoiceNotApproved = errors.New("fatura henüz onaylanmadı")
+	// ErrInvoiceAlreadyApproved fatura onaylanmış olduğu için taslak işlemi yapılamıyor
+	ErrInvoiceAlreadyApproved = errors.New("fatura zaten onaylanmış")
+	// ErrInvoiceRejected fatura GİB veya alıcı tarafından reddedildi
+	ErrInvoiceRejected = errors.New("fatura reddedildi")
+	// ErrShareDisabled firma ayarlarında fatura paylaşımı kapalı
+	ErrShareDisabled = errors.New("fatura paylaşımı kapalı")
+	// ErrInvalidEmail e-posta adresinin biçimi geçersiz
+	ErrInvalidEmail = errors.New("geçersiz e-posta adresi")
+)
+
+// InvoiceError portalın fatura oluşturma isteğini reddettiği yapılandırılmış hata.
+// Messages genel hataları, FieldErrors alan bazlı doğrulama hatalarını (ModelState) içerir.
+type InvoiceError struct {
+	Messages    []string
+	FieldErrors map[string][]string
+	Body        string
+}
+
+// Error tüm mesajları tek satırda birleştirir
+func (e *InvoiceError) Error() string {
+	parts := append([]string(nil), e.Messages...)
+
+	fields := make([]string, 0, len(e.FieldErrors))
+	for field := range e.FieldErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		for _, msg := range e.FieldErrors[field] {
+			if field == "" {
+				parts = append(parts, msg)
+			} else {
+				parts = append(parts, field+": "+msg)
+			}
+		}
+	}
+
+	return "fatura oluşturma hatası: " + strings.Join(parts, "; ")
+}
+
+// parseInvoiceError fatura oluşturma hata yanıtını parse eder.
+// Tanınan bir hata yapısı yoksa ham body ile hata döner.
+func parseInvoiceError(body []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return fmt.Errorf("fatura oluşturulamadı: %s", string(body))
+	}
+
+	invoiceErr := &InvoiceError{Body: string(body)}
+
+	for _, key := range []string{"error", "ErrorMessage", "Message"} {
+		if msg, ok := raw[key].(string); ok && strings.TrimSpace(msg) != "" {
+			invoiceErr.Messages = append(invoiceErr.Messages, strings.TrimSpace(msg))
+		}
+	}
+	invoiceErr.Messages = append(invoiceErr.Messages, stringList(raw["Errors"])...)
+
+	// ASP.NET ModelState: {"alan": ["mesaj", ...]} veya {"alan": {"Errors": [{"ErrorMessage": "..."}]}}
+	if modelState, ok := raw["ModelState"].(map[string]interface{}); ok {
+		for field, value := range modelState {
+			messages := stringList(value)
+			if entry, ok := value.(map[string]interface{}); ok {
+				messages = append(messages, stringList(entry["Errors"])...)
+			}
+			if len(messages) == 0 {
+				continue
+			}
+			if invoiceErr.FieldErrors == nil {
+				invoiceErr.FieldErrors = map[string][]string{}
+			}
+			invoiceErr.FieldErrors[field] = append(invoiceErr.FieldErrors[field], messages...)
+		}
+	}
+
+	if len(invoiceErr.Messages) == 0 && len(invoiceErr.FieldErrors) == 0 {
+		return fmt.Errorf("fatura oluşturulamadı: %s", string(body))
+	}
+	return invoiceErr
+}
+
+// stringList mesaj listesini ([]string veya [{"ErrorMessage": ...}]) string dilimine çevirir
+func stringList(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var messages []string
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			if strings.TrimSpace(v) != "" {
+				messages = append(messages, strings.TrimSpace(v))
+			}
+		case map[string]interface{}:
+			if msg, ok := v["ErrorMessage"].(string); ok && strings.TrimSpace(msg) != "" {
+				messages = append(messages, strings.TrimSpace(msg))
+			}
+		}
+	}
+	return messages
+}
+
+// operationResponse portalın işlem yanıtı
+type operationResponse struct {
+	Success      bool   `json:"Success"`
+	Message      string `json:"Message"`
+	ErrorMessage string `json:"ErrorMessage"`
+	Error        string `json:"error"`
+}
+
+// message yanıttaki hata mesajını döner
+func (r operationResponse) message() string {
+	for _, msg := range []string{r.ErrorMessage, r.Error, r.Message} {
+		if msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// SendInvoiceByEmail mevcut faturayı verilen e-posta adresine tekrar gönderir
+func (c *Client) SendInvoiceByEmail(invoiceID, email string) error {
+	if invoiceID == "" {
+		return fmt.Errorf("fatura ID gerekli")
+	}
+	if err := validateEmail(email); err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"InvoiceId": {invoiceID},
+		"Email":     {strings.TrimSpace(email)},
+		"CompanyId": {c.companyID()},
+	}
+
+	body, err := c.postWithToken("/Invoice/CreateQuick", "/Invoice/SendMail", form, "e-posta gönderme")
+	if err != nil {
+		return err
+	}
+
+	return parseOperationResponse(body, "e-posta gönderilemedi")
+}
+
+// DeleteDraftInvoice henüz onaylanmamış taslak faturayı siler.
+// Taslak yasal belge olmadığından iz bırakmadan kaldırılır. Onaylanmış fatura ise
+// GİB'e iletilmiş bir belgedir, silinemez; portal üzerinden iptal edilmesi gerekir.
+// Bu durumda ErrInvoiceAlreadyApproved, fatura yoksa ErrInvoiceNotFound döner.
+func (c *Client) DeleteDraftInvoice(invoiceID string) error {
+	if invoiceID == "" {
+		return fmt.Errorf("fatura ID gerekli")
+	}
+
+	form := url.Values{
+		"InvoiceId": {invoiceID},
+		"CompanyId": {c.companyID()},
+	}
+
+	body, err := c.postWithToken("/Invoice/CreateQuick", "/Invoice/DeleteDraft", form, "taslak silme")
+	if err != nil {
+		return err
+	}
+
+	var result operationResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("JSON parse hatası: %w", err)
+	}
+	if !result.Success && strings.Contains(strings.ToLower(result.message()), "onaylanmış") {
+		return fmt.Errorf("%w: %s", ErrInvoiceAlreadyApproved, result.message())
+	}
+
+	return parseOperationResponse(body, "taslak silinemedi")
+}
+
+// approveResponse taslak onay yanıtı
+type approveResponse struct {
+	operationResponse
+	InvoiceNumber string `json:"InvoiceNumber"`
+	ETTN          string `json:"ETTN"`
+}
+
+// ApproveInvoice taslak faturayı onaylayarak yasal faturaya çevirir ve atanan
+// fatura numarası ile ETTN'i döner. Taslak daha önce onaylanmışsa
+// ErrInvoiceAlreadyApproved, reddedilmişse ErrInvoiceRejected döner.
+func (c *Client) ApproveInvoice(draftID string) (*InvoiceResult, error) {
+	if draftID == "" {
+		return nil, fmt.Errorf("taslak ID gerekli")
+	}
+
+	form := url.Values{
+		"InvoiceId": {draftID},
+		"CompanyId": {c.companyID()},
+	}
+
+	body, err := c.postWithToken("/Invoice/CreateQuick", "/Invoice/ApproveDraft", form, "taslak onaylama")
+	if err != nil {
+		return nil, err
+	}
+
+	var result approveResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	if !result.Success {
+		lower := strings.ToLower(result.message())
+		switch {
+		case strings.Contains(lower, "onaylanmış"):
+			return nil, fmt.Errorf("%w: %s", ErrInvoiceAlreadyApproved, result.message())
+		case strings.Contains(lower, "reddedil"):
+			return nil, fmt.Errorf("%w: %s", ErrInvoiceRejected, result.message())
+		}
+		return nil, parseOperationResponse(body, "taslak onaylanamadı")
+	}
+
+	if result.InvoiceNumber == "" {
+		return nil, fmt.Errorf("fatura numarası bulunamadı: %s", string(body))
+	}
+
+	return &InvoiceResult{InvoiceNumber: result.InvoiceNumber, ETTN: result.ETTN}, nil
+}
+
+// parseOperationResponse işlem yanıtını kontrol eder ve bilinen hataları tipli hataya çevirir
+func parseOperationResponse(body []byte, action string) error {
+	var result operationResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	if result.Success {
+		return nil
+	}
+
+	msg := result.message()
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "bulunamad"):
+		return fmt.Errorf("%w: %s", ErrInvoiceNotFound, msg)
+	case strings.Contains(lower, "onay"):
+		return fmt.Errorf("%w: %s", ErrInvoiceNotApproved, msg)
+	case msg == "":
+		return fmt.Errorf("%s: %s", action, string(body))
+	default:
+		return fmt.Errorf("%s: %s", action, msg)
+	}
+}
+
+// validateEmail e-posta adresinin biçimini kontrol eder
+func validateEmail(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return fmt.Errorf("e-posta adresi zorunludur")
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return fmt.Errorf("%w: %s", ErrInvalidEmail, email)
+	}
+
+	return nil
+}
+
+// InvoiceStatus faturanın GİB işleme durumu
+type InvoiceStatus int
+
+const (
+	InvoiceStatusUnknown    InvoiceStatus = iota // Bilinmeyen durum
+	InvoiceStatusDraft                           // Taslak
+	InvoiceStatusProcessing                      // İşleniyor / GİB'e gönderiliyor
+	InvoiceStatusApproved                        // Onaylandı
+	InvoiceStatusRejected                        // Reddedildi / hata
+	InvoiceStatusCancelled                       // İptal edildi
+)
+
+// String durumun adını döner
+func (s InvoiceStatus) String() string {
+	switch s {
+	case InvoiceStatusDraft:
+		return "Draft"
+	case InvoiceStatusProcessing:
+		return "Processing"
+	case InvoiceStatusApproved:
+		return "Approved"
+	case InvoiceStatusRejected:
+		return "Rejected"
+	case InvoiceStatusCancelled:
+		return "Cancelled"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsTerminal durumun son durum olup olmadığını döner (onay, red, iptal)
+func (s InvoiceStatus) IsTerminal() bool {
+	return s == InvoiceStatusApproved || s == InvoiceStatusRejected || s == InvoiceStatusCancelled
+}
+
+// invoiceStatusResponse fatura durum yanıtı
+type invoiceStatusResponse struct {
+	Status       string `json:"StatusName"`
+	StatusDetail string `json:"StatusDescription"`
+	ErrorMessage string `json:"ErrorMessage"`
+}
+
+// parseInvoiceStatus portalın durum metnini InvoiceStatus'a çevirir. Olumsuz ("Onaylanmadı")
+// ve bekleyen ("Onay Bekliyor") ifadeler "onay" içerdiğinden onaydan önce kontrol edilir.
+func parseInvoiceStatus(text string) InvoiceStatus {
+	text = normalizeString(text)
+	switch {
+	case text == "":
+		return InvoiceStatusUnknown
+	case strings.Contains(text, "iptal"):
+		return InvoiceStatusCancelled
+	case strings.Contains(text, "onaylanmadi") || strings.Contains(text, "onaylanamadi") ||
+		hasWordPrefix(text, "red") || strings.Contains(text, "hata") || strings.Contains(text, "basarisiz"):
+		return InvoiceStatusRejected
+	case strings.Contains(text, "taslak"):
+		return InvoiceStatusDraft
+	case strings.Contains(text, "bekl") || strings.Contains(text, "kuyruk") || strings.Contains(text, "islen") || strings.Contains(text, "gonderil"):
+		return InvoiceStatusProcessing
+	case strings.Contains(text, "onay") || strings.Contains(text, "basari"):
+		return InvoiceStatusApproved
+	default:
+		return InvoiceStatusUnknown
+	}
+}
+
+// hasWordPrefix metindeki kelimelerden birinin prefix ile başlayıp başlamadığını döner
+// ("red" "Reddedildi"yi yakalar, "kredi"yi yakalamaz)
+func hasWordPrefix(text, prefix string) bool {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetInvoiceStatus faturanın güncel işleme durumunu getirir
+func (c *Client) GetInvoiceStatus(invoiceID string) (InvoiceStatus, error) {
+	if invoiceID == "" {
+		return InvoiceStatusUnknown, fmt.Errorf("fatura ID gerekli")
+	}
+
+	endpoint := fmt.Sprintf("%s/Invoice/GetInvoiceStatus?invoiceId=%s", c.config.BaseURL, url.QueryEscape(invoiceID))
+
+	req, err := c.newRequest("GET", endpoint, nil)
+	if err != nil {
+		return InvoiceStatusUnknown, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return InvoiceStatusUnknown, fmt.Errorf("fatura durum isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return InvoiceStatusUnknown, fmt.Errorf("%w: %s", ErrInvoiceNotFound, invoiceID)
+	}
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return InvoiceStatusUnknown, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	var result invoiceStatusResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return InvoiceStatusUnknown, fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	if result.ErrorMessage != "" {
+		if strings.Contains(strings.ToLower(result.ErrorMessage), "bulunamad") {
+			return InvoiceStatusUnknown, fmt.Errorf("%w: %s", ErrInvoiceNotFound, result.ErrorMessage)
+		}
+		return InvoiceStatusUnknown, fmt.Errorf("fatura durumu alınamadı: %s", result.ErrorMessage)
+	}
+
+	return parseInvoiceStatus(result.Status), nil
+}
+
+// WaitForInvoiceStatus fatura hedef duruma gelene kadar pollInterval aralıklarla sorgular.
+// Fatura hedeften farklı bir son duruma geçerse (ör. Rejected) o durumla birlikte hata döner.
+func (c *Client) WaitForInvoiceStatus(ctx context.Context, invoiceID string, target InvoiceStatus, pollInterval time.Duration) (InvoiceStatus, error) {
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	// Sorgular da ctx iptal edildiğinde sonlanır
+	client := c.WithContext(ctx)
+
+	for {
+		status, err := client.GetInvoiceStatus(invoiceID)
+		if err != nil {
+			return status, err
+		}
+		if status == target {
+			return status, nil
+		}
+		if status.IsTerminal() {
+			return status, fmt.Errorf("fatura %s durumunda, beklenen: %s", status, target)
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// InvoiceListItem fatura listesi öğesi
+type InvoiceListItem struct {
+	InvoiceId     int     `json:"InvoiceId"`
+	InvoiceNumber string  `json:"InvoiceNumber"`
+	ETTN          string  `json:"ETTN"`
+	OrderNumber   string  `json:"OrderNumber"`
+	InvoiceDate   string  `json:"InvoiceDate"`
+	RecipientId   int     `json:"IdAlici"`
+	RecipientName string  `json:"RecipientName"`
+	StatusName    string  `json:"StatusName"`
+	PayableAmount float64 `json:"PayableAmount"`
+}
+
+// InvoiceListResponse fatura listesi API yanıtı
+type InvoiceListResponse struct {
+	Draw            int               `json:"draw"`
+	RecordsTotal    int               `json:"recordsTotal"`
+	RecordsFiltered int               `json:"recordsFiltered"`
+	Data            []InvoiceListItem `json:"data"`
+}
+
+// FindInvoiceByReference Invoice.Reference ile kesilmiş faturayı arar, yoksa ErrInvoiceNotFound döner.
+// Yanıtı okunamayan bir CreateInvoice'u tekrar denemeden önce faturanın oluşup oluşmadığını anlamak için kullanılır.
+func (c *Client) FindInvoiceByReference(reference string) (*InvoiceListItem, error) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return nil, fmt.Errorf("referans gerekli")
+	}
+
+	start := 0
+	length := 200
+
+	for {
+		result, err := c.getInvoiceList(start, length, reference, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// Arama tüm kolonlarda yapıldığından referans birebir kontrol edilir
+		for _, item := range result.Data {
+			if item.OrderNumber == reference {
+				found := item
+				return &found, nil
+			}
+		}
+
+		if len(result.Data) < length {
+			break
+		}
+		start += length
+	}
+
+	return nil, fmt.Errorf("%w: referans %s", ErrInvoiceNotFound, reference)
+}
+
+// getInvoiceList fatura listesinin bir sayfasını getirir, filters ek filtre alanlarıdır
+func (c *Client) getInvoiceList(start, length int, search string, filters url.Values) (*InvoiceListResponse, error) {
+	form := url.Values{
+		"draw":            {"1"},
+		"start":           {fmt.Sprintf("%d", start)},
+		"length":          {fmt.Sprintf("%d", length)},
+		"search[value]":   {search},
+		"search[regex]":   {"false"},
+		"CompanyIdFilter": {c.companyID()},
+	}
+	for key, values := range filters {
+		form[key] = values
+	}
+
+	req, err := c.newRequest("POST", c.config.BaseURL+"/Invoice/GetInvoiceList", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fatura listesi isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	var result InvoiceListResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	return &result, nil
+}
+
+// InvoiceDetail portalda kayıtlı faturanın ayrıntıları
+type InvoiceDetail struct {
+	InvoiceId        int                 `json:"InvoiceId"`
+	InvoiceNumber    string              `json:"InvoiceNumber"`
+	ETTN             string              `json:"ETTN"`
+	InvoiceDate      string              `json:"InvoiceDate"` // 02-01-2006
+	InvoiceTime      string              `json:"InvoiceTime"`
+	RecipientId      int                 `json:"IdAlici"`
+	RecipientName    string              `json:"RecipientName"`
+	RecipientType    json.Number         `json:"RecipientType"`
+	ReceiverInboxTag string              `json:"ReceiverInboxTag"`
+	OrderNumber      string              `json:"OrderNumber"`
+	CurrencyCode     string              `json:"CurrencyCode"`
+	CrossRate        float64             `json:"CrossRate"`
+	StatusName       string              `json:"StatusName"`
+	Notes            []string            `json:"Notes"`
+	Products         []InvoiceDetailLine `json:"Products"`
+
+	TotalLineExtensionAmount float64 `json:"TotalLineExtensionAmount"`
+	TotalVATAmount           float64 `json:"TotalVATAmount"`
+	TotalTaxInclusiveAmount  float64 `json:"TotalTaxInclusiveAmount"`
+	TotalDiscountAmount      float64 `json:"TotalDiscountAmount"`
+	TotalPayableAmount       float64 `json:"TotalPayableAmount"`
+	RoundCounter             float64 `json:"RoundCounter"`
+}
+
+// InvoiceDetailLine fatura ayrıntısındaki ürün satırı
+type InvoiceDetailLine struct {
+	ProductId           int     `json:"ProductId"`
+	ProductName         string  `json:"ProductName"`
+	Quantity            float64 `json:"Quantity"`
+	UnitPrice           float64 `json:"UnitPrice"` // KDV hariç
+	VatRate             int     `json:"VatRate"`
+	VatAmount           float64 `json:"VatAmount"`
+	LineExtensionAmount float64 `json:"LineExtensionAmount"`
+	GTIPCode            string  `json:"GTipNoArcvh"`
+	ClassificationCode  string  `json:"SiniflandirmaKodu"`
+	ClassificationID    int     `json:"IdSiniflandirmaKodu"`
+	CountryOfOriginID   int     `json:"IdMensei"`
+	CountryOfOrigin     string  `json:"Mensei"`
+}
+
+// GetInvoiceDetail faturanın portalda kayıtlı ayrıntılarını (satırlar ve toplamlar) getirir
+func (c *Client) GetInvoiceDetail(invoiceID string) (*InvoiceDetail, error) {
+	if invoiceID == "" {
+		return nil, fmt.Errorf("fatura ID gerekli")
+	}
+
+	endpoint := fmt.Sprintf("%s/Invoice/GetInvoiceDetail?invoiceId=%s", c.config.BaseURL, url.QueryEscape(invoiceID))
+
+	req, err := c.newRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fatura detay isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrInvoiceNotFound, invoiceID)
+	}
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	var detail *InvoiceDetail
+	if err := json.Unmarshal(body, &detail); err != nil {
+		return nil, fmt.Errorf("JSON parse hatası: %w", err)
+	}
+	if detail == nil || detail.InvoiceId == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvoiceNotFound, invoiceID)
+	}
+
+	return detail, nil
+}
+
+// DuplicateInvoice mevcut faturayı yeni bir Invoice olarak kopyalar.
+// Numara, ETTN, tarih, referans ve irsaliye bağlantıları kopyalanmaz; döviz faturalarında
+// para birimi kopyalanır ancak güncel kur CrossRate'e ayrıca verilmelidir.
+// Dönen fatura düzenlenip CreateInvoice ile kesilebilir.
+func (c *Client) DuplicateInvoice(invoiceID string) (Invoice, error) {
+	detail, err := c.GetInvoiceDetail(invoiceID)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	invoice := Invoice{
+		CustomerID:       idString(detail.RecipientId),
+		RecipientType:    RecipientType(detail.RecipientType.String()),
+		ReceiverInboxTag: detail.ReceiverInboxTag,
+		CurrencyCode:     detail.CurrencyCode,
+	}
+
+	for _, note := range detail.Notes {
+		if strings.TrimSpace(note) != "" {
+			invoice.Notes = append(invoice.Notes, note)
+		}
+	}
+
+	for _, line := range detail.Products {
+		invoice.Products = append(invoice.Products, Product{
+			ProductID:          idString(line.ProductId),
+			Name:               line.ProductName,
+			Quantity:           line.Quantity,
+			Price:              line.UnitPrice,
+			VATRate:            line.VatRate,
+			GTIPCode:           line.GTIPCode,
+			ClassificationCode: line.ClassificationCode,
+			ClassificationID:   line.ClassificationID,
+			CountryOfOriginID:  line.CountryOfOriginID,
+			CountryOfOrigin:    line.CountryOfOrigin,
+		})
+	}
+
+	return invoice, nil
+}
+
+// TotalMismatch tek bir toplam alanındaki fark
+type TotalMismatch struct {
+	Field    string // Portal alan adı (örn. TotalVATAmount)
+	Expected Money  // Kütüphanenin hesapladığı
+	Actual   Money  // Portalda kayıtlı
+}
+
+// Discrepancy faturanın gönderilen ve portalda kayıtlı toplamları arasındaki farklar
+type Discrepancy struct {
+	InvoiceID  string
+	Mismatches []TotalMismatch
+}
+
+// Error farkları tek satırda özetler
+func (d *Discrepancy) Error() string {
+	parts := make([]string, 0, len(d.Mismatches))
+	for _, m := range d.Mismatches {
+		parts = append(parts, fmt.Sprintf("%s: beklenen %s, kayıtlı %s", m.Field, m.Expected, m.Actual))
+	}
+	return fmt.Sprintf("fatura %s toplamları uyuşmuyor: %s", d.InvoiceID, strings.Join(parts, "; "))
+}
+
+// VerifyInvoiceTotals portalda kayıtlı fatura toplamlarını expected faturadan kütüphanenin
+// hesapladığı toplamlarla karşılaştırır. Toplamlar yuvarlama ayarındaki basamakta birebir
+// eşleşmelidir; fark varsa false ve farkları içeren Discrepancy döner.
+func (c *Client) VerifyInvoiceTotals(invoiceID string, expected Invoice) (bool, *Discrepancy, error) {
+	_, totals, err := c.buildProductLines(expected)
+	if err != nil {
+		return false, nil, err
+	}
+
+	detail, err := c.GetInvoiceDetail(invoiceID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	fields := []struct {
+		name     string
+		expected Money
+		actual   float64
+	}{
+		{"TotalLineExtensionAmount", totals.LineExtension, detail.TotalLineExtensionAmount},
+		{"TotalVATAmount", totals.VAT, detail.TotalVATAmount},
+		{"TotalTaxInclusiveAmount", totals.Total, detail.TotalTaxInclusiveAmount},
+		{"TotalDiscountAmount", totals.Discount, detail.TotalDiscountAmount},
+		{"TotalPayableAmount", totals.Payable, detail.TotalPayableAmount},
+	}
+
+	discrepancy := &Discrepancy{InvoiceID: invoiceID}
+	for _, field := range fields {
+		// Portal tutarları float döndüğünden karşılaştırma yuvarlanmış değerlerle yapılır
+		actual := c.round(NewMoney(field.actual))
+		if c.round(field.expected).Cmp(actual) != 0 {
+			discrepancy.Mismatches = append(discrepancy.Mismatches, TotalMismatch{
+				Field:    field.name,
+				Expected: c.round(field.expected),
+				Actual:   actual,
+			})
+		}
+	}
+
+	if len(discrepancy.Mismatches) > 0 {
+		return false, discrepancy, nil
+	}
+	return true, nil, nil
+}
+
+// shareLinkResponse paylaşım linki yanıtı
+type shareLinkResponse struct {
+	operationResponse
+	URL   string `json:"Url"`
+	Token string `json:"Token"`
+}
+
+// GetInvoiceShareLink alıcının faturayı görüntüleyebileceği herkese açık linki döner.
+// Paylaşım kapalıysa ErrShareDisabled, fatura henüz işleniyorsa ErrInvoiceNotApproved döner.
+func (c *Client) GetInvoiceShareLink(invoiceID string) (string, error) {
+	if invoiceID == "" {
+		return "", fmt.Errorf("fatura ID gerekli")
+	}
+
+	endpoint := fmt.Sprintf("%s/Invoice/GetShareLink?invoiceId=%s", c.config.BaseURL, url.QueryEscape(invoiceID))
+
+	req, err := c.newRequest("GET", endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("paylaşım linki isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %s", ErrInvoiceNotFound, invoiceID)
+	}
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	var result shareLinkResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	if !result.Success {
+		// Türkçe büyük harfli mesajlar (PAYLAŞIM, İŞLENİYOR) da eşleşsin diye ASCII'ye katlanır
+		normalized := normalizeString(result.message())
+		switch {
+		case strings.Contains(normalized, "paylasim"):
+			return "", fmt.Errorf("%w: %s", ErrShareDisabled, result.message())
+		case strings.Contains(normalized, "islen"):
+			return "", fmt.Errorf("%w: %s", ErrInvoiceNotApproved, result.message())
+		}
+		return "", parseOperationResponse(body, "paylaşım linki alınamadı")
+	}
+
+	switch {
+	case result.URL != "" && strings.HasPrefix(result.URL, "/"):
+		return c.config.BaseURL + result.URL, nil
+	case result.URL != "":
+		return result.URL, nil
+	case result.Token != "":
+		return fmt.Sprintf("%s/Public/Invoice?token=%s", c.config.BaseURL, url.QueryEscape(result.Token)), nil
+	}
+
+	return "", fmt.Errorf("paylaşım linki bulunamadı: %s", string(body))
+}
+
+// InvoiceArchive faturanın UBL XML ve PDF dosyaları
+type InvoiceArchive struct {
+	XML []byte
+	PDF []byte
+}
+
+// GetInvoiceXML faturanın UBL-TR XML belgesini indirir
+func (c *Client) GetInvoiceXML(invoiceID string) ([]byte, error) {
+	if invoiceID == "" {
+		return nil, fmt.Errorf("fatura ID gerekli")
+	}
+
+	body, err := c.downloadInvoiceFile("/Invoice/DownloadUbl", invoiceID, "XML indirme")
+	if err != nil {
+		return nil, err
+	}
+
+	if !isXMLDocument(body) {
+		return nil, fmt.Errorf("XML yerine beklenmeyen içerik döndü: %.200s", string(body))
+	}
+
+	return body, nil
+}
+
+// GetInvoiceArchive faturanın XML ve PDF dosyalarını içeren zip arşivini indirir ve açar
+func (c *Client) GetInvoiceArchive(invoiceID string) (*InvoiceArchive, error) {
+	if invoiceID == "" {
+		return nil, fmt.Errorf("fatura ID gerekli")
+	}
+
+	body, err := c.downloadInvoiceFile("/Invoice/DownloadZip", invoiceID, "arşiv indirme")
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("zip arşivi açılamadı: %w", err)
+	}
+
+	archive := &InvoiceArchive{}
+	for _, file := range reader.File {
+		ext := strings.ToLower(path.Ext(file.Name))
+		if ext != ".xml" && ext != ".pdf" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s açılamadı: %w", file.Name, err)
+		}
+		content, err := c.readBody(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s okunamadı: %w", file.Name, err)
+		}
+
+		if ext == ".xml" {
+			archive.XML = content
+		} else {
+			archive.PDF = content
+		}
+	}
+
+	if archive.XML == nil {
+		return nil, fmt.Errorf("arşivde XML bulunamadı")
+	}
+	if !isXMLDocument(archive.XML) {
+		return nil, fmt.Errorf("arşivdeki XML geçersiz")
+	}
+
+	return archive, nil
+}
+
+// downloadInvoiceFile fatura dosyasını indirir
+func (c *Client) downloadInvoiceFile(path, invoiceID, action string) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s%s?invoiceId=%s", c.config.BaseURL, path, url.QueryEscape(invoiceID))
+
+	req, err := c.newRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s isteği başarısız: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrInvoiceNotFound, invoiceID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s başarısız, status: %d", action, resp.StatusCode)
+	}
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	return body, nil
+}
+
+// isXMLDocument içeriğin HTML hata sayfası değil XML belgesi olup olmadığını kontrol eder
+func isXMLDocument(content []byte) bool {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	if !bytes.HasPrefix(trimmed, []byte("<")) {
+		return false
+	}
+
+	if len(trimmed) > 512 {
+		trimmed = trimmed[:512]
+	}
+	head := strings.ToLower(string(trimmed))
+	if strings.Contains(head, "<html") || strings.Contains(head, "<!doctype html") {
+		return false
+	}
+
+	return strings.HasPrefix(head, "<?xml") || strings.Contains(head, "invoice")
+}
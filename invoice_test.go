@@ -0,0 +1,72 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseInvoiceStatus(t *testing.T) {
+	tests := []struct {
+		text string
+		want InvoiceStatus
+	}{
+		{"", InvoiceStatusUnknown},
+		{"Taslak", InvoiceStatusDraft},
+		{"Onaylandı", InvoiceStatusApproved},
+		{"ONAYLANDI", InvoiceStatusApproved},
+		{"Başarılı", InvoiceStatusApproved},
+		{"GİB'e Başarıyla İletildi", InvoiceStatusApproved},
+		{"Onay Bekliyor", InvoiceStatusProcessing},
+		{"Onay Bekleniyor", InvoiceStatusProcessing},
+		{"GİB Onayı Bekleniyor", InvoiceStatusProcessing},
+		{"Kuyrukta", InvoiceStatusProcessing},
+		{"İşleniyor", InvoiceStatusProcessing},
+		{"GİB'e Gönderildi", InvoiceStatusProcessing},
+		{"Onaylanmadı", InvoiceStatusRejected},
+		{"ONAYLANAMADI", InvoiceStatusRejected},
+		{"Reddedildi", InvoiceStatusRejected},
+		{"Red", InvoiceStatusRejected},
+		{"Alıcı Tarafından Reddedildi", InvoiceStatusRejected},
+		{"Hata Alındı", InvoiceStatusRejected},
+		{"Başarısız", InvoiceStatusRejected},
+		{"İptal Edildi", InvoiceStatusCancelled},
+		{"Kredi Kartı", InvoiceStatusUnknown},
+		{"Bilinmeyen Durum", InvoiceStatusUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := parseInvoiceStatus(tt.text); got != tt.want {
+			t.Errorf("parseInvoiceStatus(%q) = %s, beklenen %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGetInvoiceShareLinkErrors(t *testing.T) {
+	tests := []struct {
+		message string
+		want    error
+	}{
+		{"Fatura paylaşımı kapalı.", ErrShareDisabled},
+		{"PAYLAŞIM KAPALI", ErrShareDisabled},
+		{"Fatura henüz işleniyor.", ErrInvoiceNotApproved},
+		{"FATURA İŞLENİYOR", ErrInvoiceNotApproved},
+	}
+
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"Success": false, "ErrorMessage": tt.message})
+		}))
+
+		client, err := NewClient("1", WithBaseURL(srv.URL))
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		if _, err := client.GetInvoiceShareLink("5001"); !errors.Is(err, tt.want) {
+			t.Errorf("%q: hata = %v, beklenen %v", tt.message, err, tt.want)
+		}
+		srv.Close()
+	}
+}
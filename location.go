@@ -3,7 +3,10 @@ package nettefatura
 import (
 	_ "embed"
 	"encoding/json"
+	"fmt"
+	"os"
 	"strings"
+	"sync/atomic"
 )
 
 //go:embed assets/il-ilce-data.json
@@ -24,45 +27,130 @@ type District struct {
 	Name string `json:"name"`
 }
 
-var locationData *IlIlceData
+// locationData kullanımdaki il/ilçe veri seti
+var locationData atomic.Pointer[IlIlceData]
 
 func init() {
-	locationData = &IlIlceData{}
-	if err := json.Unmarshal(ilIlceDataJSON, locationData); err != nil {
+	if err := ResetLocationData(); err != nil {
 		panic("failed to load il-ilce data: " + err.Error())
 	}
 }
 
-// normalizeString Türkçe karakterleri normalize eder ve küçük harfe çevirir
+// currentLocationData kullanımdaki veri setini döner
+func currentLocationData() *IlIlceData {
+	return locationData.Load()
+}
+
+// SetLocationData gömülü il/ilçe verisi yerine verilen veri setini kullanır.
+// Veri doğrulanır ve kopyalanır; geçersizse mevcut veri değişmez.
+func SetLocationData(data IlIlceData) error {
+	if err := validateLocationData(&data); err != nil {
+		return err
+	}
+
+	clone := &IlIlceData{
+		Cities:    append([]City(nil), data.Cities...),
+		Districts: make(map[string][]District, len(data.Districts)),
+	}
+	for cityID, districts := range data.Districts {
+		clone.Districts[cityID] = append([]District(nil), districts...)
+	}
+
+	locationData.Store(clone)
+	return nil
+}
+
+// LoadLocationDataFromFile il/ilçe veri setini gömülü veriyle aynı formattaki JSON dosyasından yükler
+func LoadLocationDataFromFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("il/ilçe dosyası okunamadı: %w", err)
+	}
+
+	var data IlIlceData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	return SetLocationData(data)
+}
+
+// ResetLocationData gömülü il/ilçe veri setine geri döner
+func ResetLocationData() error {
+	var data IlIlceData
+	if err := json.Unmarshal(ilIlceDataJSON, &data); err != nil {
+		return err
+	}
+	return SetLocationData(data)
+}
+
+// validateLocationData veri setinin yapısını kontrol eder
+func validateLocationData(data *IlIlceData) error {
+	if len(data.Cities) == 0 {
+		return fmt.Errorf("il listesi boş olamaz")
+	}
+
+	cityIDs := make(map[string]bool, len(data.Cities))
+	for _, city := range data.Cities {
+		if city.ID == "" || strings.TrimSpace(city.Name) == "" {
+			return fmt.Errorf("il ID ve adı zorunludur: %+v", city)
+		}
+		if cityIDs[city.ID] {
+			return fmt.Errorf("tekrarlanan il ID: %s", city.ID)
+		}
+		cityIDs[city.ID] = true
+	}
+
+	for cityID, districts := range data.Districts {
+		if !cityIDs[cityID] {
+			return fmt.Errorf("ilçeler bilinmeyen il ID'sine ait: %s", cityID)
+		}
+		for _, district := range districts {
+			if district.ID <= 0 || strings.TrimSpace(district.Name) == "" {
+				return fmt.Errorf("il %s: geçersiz ilçe %+v", cityID, district)
+			}
+		}
+	}
+
+	return nil
+}
+
+// turkishUpperReplacer Türkçe büyük I/İ harflerini küçük harfe çevirmeden önce eşler
+var turkishUpperReplacer = strings.NewReplacer(
+	"İ", "i",
+	"I", "ı",
+)
+
+// turkishFoldReplacer küçük Türkçe karakterleri ASCII karşılıklarına çevirir
+var turkishFoldReplacer = strings.NewReplacer(
+	"ğ", "g",
+	"ü", "u",
+	"ş", "s",
+	"ı", "i",
+	"ö", "o",
+	"ç", "c",
+	"â", "a",
+	"î", "i",
+	"û", "u",
+	"\u0307", "", // Birleşik üst nokta (i̇)
+)
+
+// normalizeString Türkçe karakterleri normalize eder ve küçük harfe çevirir.
+// Türkçe büyük/küçük harf kuralları uygulanır: "I" -> "ı" ve "İ" -> "i" küçültmeden önce
+// çevrilir, ardından tüm Türkçe karakterler ASCII'ye katlanır (IĞDIR = Iğdır = igdir).
 func normalizeString(s string) string {
-	s = strings.ToLower(s)
 	s = strings.TrimSpace(s)
+	s = turkishUpperReplacer.Replace(s)
+	s = strings.ToLower(s)
 
-	// Türkçe karakter dönüşümleri
-	replacer := strings.NewReplacer(
-		"ğ", "g",
-		"ü", "u",
-		"ş", "s",
-		"ı", "i",
-		"ö", "o",
-		"ç", "c",
-		"İ", "i",
-		"I", "i",
-		"Ğ", "g",
-		"Ü", "u",
-		"Ş", "s",
-		"Ö", "o",
-		"Ç", "c",
-	)
-
-	return replacer.Replace(s)
+	return turkishFoldReplacer.Replace(s)
 }
 
 // GetCityID il adından il ID'sini bulur
 func GetCityID(cityName string) string {
 	normalized := normalizeString(cityName)
 
-	for _, city := range locationData.Cities {
+	for _, city := range currentLocationData().Cities {
 		if normalizeString(city.Name) == normalized {
 			return city.ID
 		}
@@ -73,55 +161,66 @@ func GetCityID(cityName string) string {
 
 // GetDistrictID il ID'si ve ilçe adından ilçe ID'sini bulur
 func GetDistrictID(cityID, districtName string) int {
-	districts, ok := locationData.Districts[cityID]
+	districts, ok := currentLocationData().Districts[cityID]
 	if !ok {
 		return -1
 	}
 	normalized := normalizeString(districtName)
-	isLookingForMerkez := strings.Contains(normalized, normalizeString("Merkez"))
+
 	// Önce direkt eşleşme dene
 	for _, district := range districts {
-		if strings.Contains(normalizeString(district.Name), "merkez") && isLookingForMerkez {
-			return district.ID
-		}
 		if normalizeString(district.Name) == normalized {
 			return district.ID
 		}
 	}
 
-	// Eğer bulamazsa ve sadece il adı verilmişse merkez ilçeyi ara
-	var cityName string
-	for _, city := range locationData.Cities {
-		if city.ID == cityID {
-			cityName = city.Name
-			break
-		}
+	// "Merkez" içeren veya sadece il adı verilmişse merkez ilçeyi ara
+	cityNameNormalized := ""
+	if cityName := GetCityName(cityID); cityName != "-1" {
+		cityNameNormalized = normalizeString(cityName)
 	}
 
-	if cityName != "" {
-		cityNameNormalized := normalizeString(cityName)
+	if strings.Contains(normalized, "merkez") || (cityNameNormalized != "" && normalized == cityNameNormalized) {
+		return resolveMerkezDistrict(cityNameNormalized, districts)
+	}
 
-		// Sadece il adıyla tam eşleşiyorsa merkez ilçeleri ara
-		if normalized == cityNameNormalized {
-			// Önce il adını içeren merkez ilçeleri ara
-			for _, district := range districts {
-				districtNormalized := normalizeString(district.Name)
-				if strings.Contains(districtNormalized, "merkez") &&
-					strings.Contains(districtNormalized, cityNameNormalized) {
-					return district.ID
-				}
+	return -1
+}
+
+// resolveMerkezDistrict ildeki merkez ilçeyi belirli bir öncelik sırasıyla bulur:
+// "<İl> Merkez", il adıyla aynı isimli ilçe, "Merkez", "merkez" kelimesini içeren ilçe
+// ve son olarak adında "merkez" geçen herhangi bir ilçe (ör. Merkezefendi).
+func resolveMerkezDistrict(cityNameNormalized string, districts []District) int {
+	var exact []string
+	if cityNameNormalized != "" {
+		exact = append(exact, cityNameNormalized+" merkez", cityNameNormalized)
+	}
+	exact = append(exact, "merkez")
+
+	for _, target := range exact {
+		for _, district := range districts {
+			if normalizeString(district.Name) == target {
+				return district.ID
 			}
+		}
+	}
 
-			// Bulamazsa herhangi bir merkez ilçe
-			for _, district := range districts {
-				districtNormalized := normalizeString(district.Name)
-				if strings.Contains(districtNormalized, "merkez") {
-					return district.ID
-				}
+	// Ayrı kelime olarak "merkez" geçen ilçe
+	for _, district := range districts {
+		for _, word := range strings.Fields(normalizeString(district.Name)) {
+			if word == "merkez" {
+				return district.ID
 			}
 		}
 	}
 
+	// Adında "merkez" geçen herhangi bir ilçe
+	for _, district := range districts {
+		if strings.Contains(normalizeString(district.Name), "merkez") {
+			return district.ID
+		}
+	}
+
 	return -1
 }
 
@@ -137,7 +236,7 @@ func GetDistrictIDByNames(cityName, districtName string) int {
 
 // GetCityName il ID'sinden il adını bulur
 func GetCityName(cityID string) string {
-	for _, city := range locationData.Cities {
+	for _, city := range currentLocationData().Cities {
 		if city.ID == cityID {
 			return city.Name
 		}
@@ -147,7 +246,7 @@ func GetCityName(cityID string) string {
 
 // GetDistrictName ilçe ID'sinden ilçe adını bulur
 func GetDistrictName(cityID string, districtID int) string {
-	districts, ok := locationData.Districts[cityID]
+	districts, ok := currentLocationData().Districts[cityID]
 	if !ok {
 		return "-1"
 	}
@@ -160,3 +259,158 @@ func GetDistrictName(cityID string, districtID int) string {
 
 	return "-1"
 }
+
+// GetCityIDFuzzy il adından en yakın ili bulur ve benzerlik skorunu döner.
+// Skor threshold altında kalırsa "-1" ve 0 döner. Kısaltmalar için (Afyon -> Afyonkarahisar)
+// il adının girdiyle başlaması ek puan sayılır.
+func GetCityIDFuzzy(cityName string, threshold float64) (string, float64) {
+	normalized := normalizeString(cityName)
+	if normalized == "" {
+		return "-1", 0
+	}
+
+	bestID := "-1"
+	bestScore := 0.0
+	for _, city := range currentLocationData().Cities {
+		score := locationSimilarity(normalized, normalizeString(city.Name))
+		if score > bestScore {
+			bestID = city.ID
+			bestScore = score
+		}
+	}
+
+	if bestScore < threshold {
+		return "-1", 0
+	}
+	return bestID, bestScore
+}
+
+// GetDistrictIDFuzzy il ID'si ve ilçe adından en yakın ilçeyi bulur ve benzerlik skorunu döner.
+// Skor threshold altında kalırsa -1 ve 0 döner.
+func GetDistrictIDFuzzy(cityID, districtName string, threshold float64) (int, float64) {
+	// Tam eşleşme ve merkez kuralları öncelikli
+	if id := GetDistrictID(cityID, districtName); id != -1 {
+		return id, 1.0
+	}
+
+	districts, ok := currentLocationData().Districts[cityID]
+	if !ok {
+		return -1, 0
+	}
+	normalized := normalizeString(districtName)
+	if normalized == "" {
+		return -1, 0
+	}
+
+	bestID := -1
+	bestScore := 0.0
+	for _, district := range districts {
+		score := locationSimilarity(normalized, normalizeString(district.Name))
+		if score > bestScore {
+			bestID = district.ID
+			bestScore = score
+		}
+	}
+
+	if bestScore < threshold {
+		return -1, 0
+	}
+	return bestID, bestScore
+}
+
+// locationSimilarity normalize edilmiş girdi ile aday isim arasındaki skoru hesaplar
+func locationSimilarity(input, candidate string) float64 {
+	score := calculateSimilarityScore(input, candidate)
+
+	// Ön ek eşleşmesi (en az 3 karakter) kısaltma kabul edilir
+	if len(input) >= 3 && strings.HasPrefix(candidate, input) {
+		prefixScore := 0.8 + 0.2*score
+		if prefixScore > score {
+			score = prefixScore
+		}
+	}
+
+	return score
+}
+
+// GetCities tüm illeri döner (veri setinin kopyası)
+func GetCities() []City {
+	data := currentLocationData()
+	cities := make([]City, len(data.Cities))
+	copy(cities, data.Cities)
+	return cities
+}
+
+// GetDistricts il ID'sine ait ilçeleri döner (veri setinin kopyası), il bulunamazsa nil
+func GetDistricts(cityID string) []District {
+	districts, ok := currentLocationData().Districts[cityID]
+	if !ok {
+		return nil
+	}
+
+	result := make([]District, len(districts))
+	copy(result, districts)
+	return result
+}
+
+// GetDistrictsByCityName il adına ait ilçeleri döner (veri setinin kopyası), il bulunamazsa nil
+func GetDistrictsByCityName(cityName string) []District {
+	cityID := GetCityID(cityName)
+	if cityID == "-1" {
+		return nil
+	}
+
+	return GetDistricts(cityID)
+}
+
+// plateCodeCities plaka kodu sırasıyla il adları (01 Adana ... 81 Düzce).
+// Portalın il ID'leri (City.ID) plaka kodlarıyla aynı değildir; eşleştirme il adı üzerinden yapılır.
+var plateCodeCities = []string{
+	"Adana", "Adıyaman", "Afyonkarahisar", "Ağrı", "Amasya", "Ankara",
+	"Antalya", "Artvin", "Aydın", "Balıkesir", "Bilecik", "Bingöl",
+	"Bitlis", "Bolu", "Burdur", "Bursa", "Çanakkale", "Çankırı",
+	"Çorum", "Denizli", "Diyarbakır", "Edirne", "Elazığ", "Erzincan",
+	"Erzurum", "Eskişehir", "Gaziantep", "Giresun", "Gümüşhane", "Hakkari",
+	"Hatay", "Isparta", "Mersin", "İstanbul", "İzmir", "Kars",
+	"Kastamonu", "Kayseri", "Kırklareli", "Kırşehir", "Kocaeli", "Konya",
+	"Kütahya", "Malatya", "Manisa", "Kahramanmaraş", "Mardin", "Muğla",
+	"Muş", "Nevşehir", "Niğde", "Ordu", "Rize", "Sakarya",
+	"Samsun", "Siirt", "Sinop", "Sivas", "Tekirdağ", "Tokat",
+	"Trabzon", "Tunceli", "Şanlıurfa", "Uşak", "Van", "Yozgat",
+	"Zonguldak", "Aksaray", "Bayburt", "Karaman", "Kırıkkale", "Batman",
+	"Şırnak", "Bartın", "Ardahan", "Iğdır", "Yalova", "Karabük",
+	"Kilis", "Osmaniye", "Düzce",
+}
+
+// GetCityByPlateCode plaka kodundan (1-81) ili bulur
+func GetCityByPlateCode(code int) (City, bool) {
+	if code < 1 || code > len(plateCodeCities) {
+		return City{}, false
+	}
+
+	normalized := normalizeString(plateCodeCities[code-1])
+	for _, city := range currentLocationData().Cities {
+		if normalizeString(city.Name) == normalized {
+			return city, true
+		}
+	}
+
+	return City{}, false
+}
+
+// GetPlateCode il ID'sinden plaka kodunu bulur, bulamazsa -1 döner
+func GetPlateCode(cityID string) int {
+	cityName := GetCityName(cityID)
+	if cityName == "-1" {
+		return -1
+	}
+
+	normalized := normalizeString(cityName)
+	for i, name := range plateCodeCities {
+		if normalizeString(name) == normalized {
+			return i + 1
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,132 @@
+package nettefatura
+
+import "testing"
+
+func TestResolveMerkezDistrict(t *testing.T) {
+	tests := []struct {
+		name      string
+		city      string
+		districts []District
+		want      int
+	}{
+		{
+			name: "<İl> Merkez önce gelir",
+			city: "Bolu",
+			districts: []District{
+				{ID: 1, Name: "Merkez"},
+				{ID: 2, Name: "Bolu"},
+				{ID: 3, Name: "BOLU MERKEZ"},
+			},
+			want: 3,
+		},
+		{
+			name: "il adıyla aynı isimli ilçe",
+			city: "Iğdır",
+			districts: []District{
+				{ID: 1, Name: "Aralık"},
+				{ID: 2, Name: "Merkez"},
+				{ID: 3, Name: "IĞDIR"},
+			},
+			want: 3,
+		},
+		{
+			name: "sadece Merkez",
+			city: "Kars",
+			districts: []District{
+				{ID: 1, Name: "Sarıkamış"},
+				{ID: 2, Name: "Merkez"},
+			},
+			want: 2,
+		},
+		{
+			name: "kelime olarak merkez içeren ilçe",
+			city: "Denizli",
+			districts: []District{
+				{ID: 1, Name: "Merkezefendi"},
+				{ID: 2, Name: "Eski Merkez"},
+			},
+			want: 2,
+		},
+		{
+			name: "adında merkez geçen herhangi bir ilçe",
+			city: "Denizli",
+			districts: []District{
+				{ID: 1, Name: "Pamukkale"},
+				{ID: 2, Name: "Merkezefendi"},
+			},
+			want: 2,
+		},
+		{
+			name: "il adı verilmemiş",
+			districts: []District{
+				{ID: 1, Name: "Ankara Merkez"},
+				{ID: 2, Name: "Merkez"},
+			},
+			want: 2,
+		},
+		{
+			name:      "merkez yok",
+			city:      "İstanbul",
+			districts: []District{{ID: 1, Name: "Kadıköy"}, {ID: 2, Name: "Üsküdar"}},
+			want:      -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveMerkezDistrict(normalizeString(tt.city), tt.districts); got != tt.want {
+				t.Errorf("resolveMerkezDistrict = %d, beklenen %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"I", "i"},
+		{"ı", "i"},
+		{"İ", "i"},
+		{"i̇", "i"}, // i + birleşik üst nokta (strings.ToLower("İ") sonucu)
+		{"IĞDIR", "igdir"},
+		{"Iğdır", "igdir"},
+		{"ISPARTA", "isparta"},
+		{"İSTANBUL", "istanbul"},
+		{"ISTANBUL", "istanbul"},
+		{"  Şişli  ", "sisli"},
+		{"ÇANAKKALE", "canakkale"},
+		{"Gümüşhane", "gumushane"},
+		{"Kâhta", "kahta"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeString(tt.in); got != tt.want {
+			t.Errorf("normalizeString(%q) = %q, beklenen %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetCityIDTurkishCase(t *testing.T) {
+	tests := []struct {
+		canonical string
+		variants  []string
+	}{
+		{"Iğdır", []string{"IĞDIR", "ığdır", "Igdir", "IGDIR"}},
+		{"İstanbul", []string{"ISTANBUL", "İSTANBUL", "istanbul", "Istanbul"}},
+		{"Isparta", []string{"ISPARTA", "ısparta"}},
+		{"İzmir", []string{"IZMIR", "İZMİR", "izmir"}},
+	}
+
+	for _, tt := range tests {
+		want := GetCityID(tt.canonical)
+		if want == "-1" {
+			t.Fatalf("%s gömülü veride bulunamadı", tt.canonical)
+		}
+		for _, variant := range tt.variants {
+			if got := GetCityID(variant); got != want {
+				t.Errorf("GetCityID(%q) = %s, beklenen %s (%s)", variant, got, want, tt.canonical)
+			}
+		}
+	}
+}
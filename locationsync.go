@@ -0,0 +1,233 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// selectListItem portalın açılır liste uç noktalarının döndüğü öğe (ASP.NET SelectListItem)
+type selectListItem struct {
+	Value string `json:"Value"`
+	Text  string `json:"Text"`
+}
+
+// getSelectList açılır liste uç noktasını çağırıp boş/"Seçiniz" öğeleri atlayarak döner
+func (c *Client) getSelectList(path, action string) ([]selectListItem, error) {
+	req, err := c.newRequest("GET", c.config.BaseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s isteği başarısız: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s başarısız, status: %d", action, resp.StatusCode)
+	}
+
+	var items []selectListItem
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	result := items[:0]
+	for _, item := range items {
+		item.Value = strings.TrimSpace(item.Value)
+		item.Text = strings.TrimSpace(item.Text)
+		if item.Value == "" || item.Value == "0" || item.Value == "-1" {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// GetPortalCities portalın müşteri formundaki il listesini getirir.
+// Gömülü veri setindeki ID'leri portalın kabul ettikleriyle karşılaştırmak için kullanılır.
+func (c *Client) GetPortalCities() ([]City, error) {
+	items, err := c.getSelectList("/Recipient/GetIlList", "il listesi")
+	if err != nil {
+		return nil, err
+	}
+
+	cities := make([]City, 0, len(items))
+	for _, item := range items {
+		cities = append(cities, City{ID: item.Value, Name: item.Text})
+	}
+	return cities, nil
+}
+
+// GetPortalDistricts portalın müşteri formunda ile ait ilçe listesini getirir
+func (c *Client) GetPortalDistricts(cityID string) ([]District, error) {
+	if cityID == "" {
+		return nil, fmt.Errorf("il ID gerekli")
+	}
+
+	items, err := c.getSelectList("/Recipient/GetIlceList?ilId="+url.QueryEscape(cityID), "ilçe listesi")
+	if err != nil {
+		return nil, err
+	}
+
+	districts := make([]District, 0, len(items))
+	for _, item := range items {
+		id, err := strconv.Atoi(item.Value)
+		if err != nil {
+			return nil, fmt.Errorf("geçersiz ilçe ID: %q (%s)", item.Value, item.Text)
+		}
+		districts = append(districts, District{ID: id, Name: item.Text})
+	}
+	return districts, nil
+}
+
+// GetPortalLocationData portalın tüm il ve ilçe listesini gömülü veriyle aynı yapıda getirir.
+// Her il için ayrı istek yapıldığından (81+1 istek) seyrek, örn. sürüm güncellemelerinde çağrılmalıdır.
+// Sonuç CompareLocationData ile karşılaştırılabilir veya SetLocationData ile kullanılabilir.
+func (c *Client) GetPortalLocationData() (*IlIlceData, error) {
+	cities, err := c.GetPortalCities()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &IlIlceData{
+		Cities:    cities,
+		Districts: make(map[string][]District, len(cities)),
+	}
+	for _, city := range cities {
+		districts, err := c.GetPortalDistricts(city.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s ilçeleri alınamadı: %w", city.Name, err)
+		}
+		data.Districts[city.ID] = districts
+	}
+
+	return data, nil
+}
+
+// LocationMismatchKind il/ilçe uyuşmazlığının türü
+type LocationMismatchKind int
+
+const (
+	// LocationMissingInPortal gömülü veride olup portalda olmayan kayıt
+	LocationMissingInPortal LocationMismatchKind = iota
+	// LocationMissingLocally portalda olup gömülü veride olmayan kayıt
+	LocationMissingLocally
+	// LocationNameMismatch aynı ID'nin iki tarafta farklı isimle bulunması
+	LocationNameMismatch
+)
+
+// String uyuşmazlık türünün açıklaması
+func (k LocationMismatchKind) String() string {
+	switch k {
+	case LocationMissingInPortal:
+		return "portalda yok"
+	case LocationMissingLocally:
+		return "gömülü veride yok"
+	case LocationNameMismatch:
+		return "isim farklı"
+	}
+	return fmt.Sprintf("LocationMismatchKind(%d)", int(k))
+}
+
+// LocationMismatch gömülü il/ilçe verisi ile portal verisi arasındaki fark.
+// DistrictID sıfırsa fark il düzeyindedir.
+type LocationMismatch struct {
+	Kind       LocationMismatchKind
+	CityID     string
+	DistrictID int
+	LocalName  string // Kullanımdaki veri setindeki isim
+	PortalName string // Portaldaki isim
+}
+
+// CompareLocationData kullanımdaki il/ilçe verisini (gömülü veya SetLocationData ile
+// yüklenen) portal verisiyle karşılaştırır. İsimler büyük/küçük harf ve Türkçe karakter
+// farkları gözetilmeden karşılaştırılır. Sonuç il ve ilçe ID'sine göre sıralıdır.
+func CompareLocationData(portal *IlIlceData) []LocationMismatch {
+	local := currentLocationData()
+	var mismatches []LocationMismatch
+
+	localCities := make(map[string]string, len(local.Cities))
+	for _, city := range local.Cities {
+		localCities[city.ID] = city.Name
+	}
+	portalCities := make(map[string]string, len(portal.Cities))
+	for _, city := range portal.Cities {
+		portalCities[city.ID] = city.Name
+	}
+
+	for id, localName := range localCities {
+		portalName, ok := portalCities[id]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, LocationMismatch{Kind: LocationMissingInPortal, CityID: id, LocalName: localName})
+		case normalizeString(localName) != normalizeString(portalName):
+			mismatches = append(mismatches, LocationMismatch{Kind: LocationNameMismatch, CityID: id, LocalName: localName, PortalName: portalName})
+		}
+	}
+	for id, portalName := range portalCities {
+		if _, ok := localCities[id]; !ok {
+			mismatches = append(mismatches, LocationMismatch{Kind: LocationMissingLocally, CityID: id, PortalName: portalName})
+		}
+	}
+
+	// İlçeler yalnızca iki tarafta da bulunan iller için karşılaştırılır
+	for cityID := range localCities {
+		if _, ok := portalCities[cityID]; !ok {
+			continue
+		}
+		mismatches = append(mismatches, compareDistricts(cityID, local.Districts[cityID], portal.Districts[cityID])...)
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].CityID != mismatches[j].CityID {
+			return parseIntOrZero(mismatches[i].CityID) < parseIntOrZero(mismatches[j].CityID)
+		}
+		return mismatches[i].DistrictID < mismatches[j].DistrictID
+	})
+
+	return mismatches
+}
+
+// compareDistricts bir ilin ilçelerini karşılaştırır
+func compareDistricts(cityID string, local, portal []District) []LocationMismatch {
+	var mismatches []LocationMismatch
+
+	portalNames := make(map[int]string, len(portal))
+	for _, district := range portal {
+		portalNames[district.ID] = district.Name
+	}
+	localNames := make(map[int]string, len(local))
+	for _, district := range local {
+		localNames[district.ID] = district.Name
+	}
+
+	for _, district := range local {
+		portalName, ok := portalNames[district.ID]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, LocationMismatch{Kind: LocationMissingInPortal, CityID: cityID, DistrictID: district.ID, LocalName: district.Name})
+		case normalizeString(district.Name) != normalizeString(portalName):
+			mismatches = append(mismatches, LocationMismatch{Kind: LocationNameMismatch, CityID: cityID, DistrictID: district.ID, LocalName: district.Name, PortalName: portalName})
+		}
+	}
+	for _, district := range portal {
+		if _, ok := localNames[district.ID]; !ok {
+			mismatches = append(mismatches, LocationMismatch{Kind: LocationMissingLocally, CityID: cityID, DistrictID: district.ID, PortalName: district.Name})
+		}
+	}
+
+	return mismatches
+}
@@ -0,0 +1,158 @@
+package nettefatura
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// MatchWeights müşteri eşleştirme skor ağırlıkları.
+// Skor, eşleşen alanların ağırlıklarının toplam ağırlığa oranıdır (0-1 arası).
+type MatchWeights struct {
+	Address   float64 // Adres benzerliği
+	City      float64 // İl eşitliği
+	District  float64 // İlçe eşitliği
+	Phone     float64 // Telefon eşitliği
+	Email     float64 // E-posta eşitliği
+	TaxNumber float64 // VKN/TCKN eşitliği
+}
+
+// DefaultMatchWeights varsayılan ağırlıkları döner (adres %50, il %30, ilçe %20)
+func DefaultMatchWeights() MatchWeights {
+	return MatchWeights{
+		Address:  0.5,
+		City:     0.3,
+		District: 0.2,
+	}
+}
+
+// total ağırlıkların toplamını döner
+func (w MatchWeights) total() float64 {
+	return w.Address + w.City + w.District + w.Phone + w.Email + w.TaxNumber
+}
+
+// ErrAmbiguousMatch mevcut müşteri eşleşmesinin skoru güven eşiğinin altında
+var ErrAmbiguousMatch = errors.New("müşteri eşleşmesi belirsiz")
+
+// ErrNoConfidentMatch mevcut müşteri adaylarının hiçbiri WithMatchThreshold eşiğine ulaşmadı
+var ErrNoConfidentMatch = errors.New("yeterli benzerlikte mevcut müşteri bulunamadı")
+
+// MatchResult müşteri oluşturma veya eşleştirme sonucu
+type MatchResult struct {
+	CustomerID   string
+	Created      bool          // Yeni müşteri oluşturulduysa true
+	Score        float64       // Seçilen müşterinin skoru (yeni müşteride 1)
+	Alternatives []ScoredMatch // Diğer adaylar, yüksekten düşüğe sıralı
+}
+
+// RecipientCandidate eşleştirme için aday müşteri
+type RecipientCandidate struct {
+	Recipient RecipientListItem
+	Detail    *Customer // GetRecipientDetail sonucu, alınamadıysa nil
+}
+
+// ScoredMatch skorlanmış aday müşteri
+type ScoredMatch struct {
+	Recipient RecipientListItem
+	Detail    *Customer
+	Score     float64
+}
+
+// matchScorer aday müşterileri hedef müşteriye göre skorlar
+type matchScorer struct {
+	similarity SimilarityAlgorithm
+	weights    MatchWeights
+}
+
+// defaultMatchScorer paket seviyesindeki fonksiyonların kullandığı skorlayıcı
+var defaultMatchScorer = matchScorer{similarity: SimilarityLevenshtein, weights: DefaultMatchWeights()}
+
+// ScoreRecipientMatch aday müşterinin hedef müşteriye benzerlik skorunu hesaplar (0-1 arası).
+// Varsayılan ağırlıklar kullanılır (bkz. DefaultMatchWeights). detail nil ise sadece listedeki il/ilçe bilgisi kullanılır.
+func ScoreRecipientMatch(candidate RecipientListItem, detail *Customer, target Customer) float64 {
+	return defaultMatchScorer.score(candidate, detail, target)
+}
+
+// MatchRecipients tüm adayları skorlar ve yüksekten düşüğe sıralı döner
+func MatchRecipients(candidates []RecipientCandidate, target Customer) []ScoredMatch {
+	return defaultMatchScorer.match(candidates, target)
+}
+
+// score tek bir adayı skorlar
+func (m matchScorer) score(candidate RecipientListItem, detail *Customer, target Customer) float64 {
+	total := m.weights.total()
+	if total <= 0 {
+		return 0
+	}
+
+	// Detay alınamazsa sadece listedeki bilgiyle skor hesapla
+	cityMatch := candidate.IdIl == parseIntOrZero(target.CityID)
+	districtMatch := candidate.IdIlce == parseIntOrZero(target.DistrictID)
+	phone, email, taxNumber := candidate.Telefon, candidate.Email, candidate.Vnktckn
+
+	score := 0.0
+	if detail != nil {
+		// Adres benzerliği
+		score += m.similarity.score(detail.Address, target.Address) * m.weights.Address
+		cityMatch = detail.CityID == target.CityID
+		districtMatch = detail.DistrictID == target.DistrictID
+		phone, email, taxNumber = detail.Phone, detail.Email, detail.TaxNumber
+	}
+
+	if cityMatch {
+		score += m.weights.City
+	}
+	if districtMatch {
+		score += m.weights.District
+	}
+	if samePhone(phone, target.Phone) {
+		score += m.weights.Phone
+	}
+	if email != "" && strings.EqualFold(strings.TrimSpace(email), strings.TrimSpace(target.Email)) {
+		score += m.weights.Email
+	}
+	if taxNumber != "" && strings.TrimSpace(taxNumber) == strings.TrimSpace(target.TaxNumber) {
+		score += m.weights.TaxNumber
+	}
+
+	return score / total
+}
+
+// samePhone iki telefon numarasının son 10 hanesini karşılaştırır
+func samePhone(p1, p2 string) bool {
+	d1, d2 := lastDigits(p1, 10), lastDigits(p2, 10)
+	return d1 != "" && d1 == d2
+}
+
+// lastDigits string içindeki rakamların son n tanesini döner
+func lastDigits(s string, n int) string {
+	var digits []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits = append(digits, s[i])
+		}
+	}
+	if len(digits) > n {
+		digits = digits[len(digits)-n:]
+	}
+	return string(digits)
+}
+
+// match tüm adayları skorlar ve sıralar
+func (m matchScorer) match(candidates []RecipientCandidate, target Customer) []ScoredMatch {
+	matches := make([]ScoredMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		matches = append(matches, ScoredMatch{
+			Recipient: candidate.Recipient,
+			Detail:    candidate.Detail,
+			Score:     m.score(candidate.Recipient, candidate.Detail, target),
+		})
+	}
+
+	// Eşit skorlarda listedeki sıra korunur
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
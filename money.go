@@ -0,0 +1,227 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money float yuvarlama hatası olmadan kesin ondalık tutar.
+// Sıfır değeri 0 TL'dir.
+type Money struct {
+	rat *big.Rat
+}
+
+// NewMoney float64 tutardan Money oluşturur.
+// Float'ın en kısa ondalık gösterimi kullanılır (0.1 -> 0.1, 0.1000000000000000055 değil).
+func NewMoney(amount float64) Money {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	return Money{rat: r}
+}
+
+// ParseMoney "1234.56" veya "1234,56" biçimindeki tutarı parse eder
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Money{}, fmt.Errorf("geçersiz tutar: %q", s)
+	}
+	return Money{rat: r}, nil
+}
+
+// moneyFromRat big.Rat'tan Money oluşturur
+func moneyFromRat(r *big.Rat) Money {
+	return Money{rat: r}
+}
+
+// value nil-güvenli big.Rat döner
+func (m Money) value() *big.Rat {
+	if m.rat == nil {
+		return new(big.Rat)
+	}
+	return m.rat
+}
+
+// IsZero tutar sıfır mı
+func (m Money) IsZero() bool {
+	return m.value().Sign() == 0
+}
+
+// Sign tutarın işaretini döner (-1, 0, 1)
+func (m Money) Sign() int {
+	return m.value().Sign()
+}
+
+// Add iki tutarı toplar
+func (m Money) Add(other Money) Money {
+	return moneyFromRat(new(big.Rat).Add(m.value(), other.value()))
+}
+
+// Sub tutardan diğerini çıkarır
+func (m Money) Sub(other Money) Money {
+	return moneyFromRat(new(big.Rat).Sub(m.value(), other.value()))
+}
+
+// Mul tutarı verilen çarpanla çarpar
+func (m Money) Mul(other Money) Money {
+	return moneyFromRat(new(big.Rat).Mul(m.value(), other.value()))
+}
+
+// MulRate tutarı yüzde oranla çarpar (20 -> %20)
+func (m Money) MulRate(rate int) Money {
+	return moneyFromRat(new(big.Rat).Mul(m.value(), big.NewRat(int64(rate), 100)))
+}
+
+// Div tutarı verilen bölene böler, bölen sıfırsa sıfır döner
+func (m Money) Div(other Money) Money {
+	if other.IsZero() {
+		return Money{}
+	}
+	return moneyFromRat(new(big.Rat).Quo(m.value(), other.value()))
+}
+
+// Cmp iki tutarı karşılaştırır (-1, 0, 1)
+func (m Money) Cmp(other Money) int {
+	return m.value().Cmp(other.value())
+}
+
+// Round tutarı verilen ondalık basamağa seçili yöntemle yuvarlar
+func (m Money) Round(decimals int, mode RoundingMode) Money {
+	if decimals < 0 {
+		return m
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	scaled := new(big.Rat).Mul(m.value(), new(big.Rat).SetInt(scale))
+
+	num := scaled.Num()
+	den := scaled.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if rem.Sign() != 0 {
+		step := big.NewInt(int64(num.Sign()))
+
+		// |2*rem| ile payda karşılaştırması yarım kontrolüdür
+		twiceRem := new(big.Int).Abs(rem)
+		twiceRem.Lsh(twiceRem, 1)
+		half := twiceRem.Cmp(den)
+
+		switch mode {
+		case RoundHalfEven:
+			if half > 0 || (half == 0 && quo.Bit(0) == 1) {
+				quo.Add(quo, step)
+			}
+		case RoundDown:
+			// Kesme
+		case RoundUp:
+			quo.Add(quo, step)
+		default:
+			if half >= 0 {
+				quo.Add(quo, step)
+			}
+		}
+	}
+
+	return moneyFromRat(new(big.Rat).SetFrac(quo, scale))
+}
+
+// Float64 tutarı en yakın float64 değere çevirir
+func (m Money) Float64() float64 {
+	f, _ := m.value().Float64()
+	return f
+}
+
+// StringFixed tutarı verilen ondalık basamakla yazar
+func (m Money) StringFixed(decimals int) string {
+	return m.value().FloatString(decimals)
+}
+
+// String tutarı iki ondalık basamakla yazar
+func (m Money) String() string {
+	return m.StringFixed(2)
+}
+
+// exactString tutarı kayıpsız yazar. Sonlu ondalık gösterimi varsa ondalık ("12.345"),
+// yoksa kesir ("1/3") kullanılır; ikisi de ParseMoney ile geri okunabilir.
+func (m Money) exactString() string {
+	r := m.value()
+
+	// Payda yalnızca 2 ve 5 çarpanlarından oluşuyorsa ondalık sonludur
+	den := new(big.Int).Set(r.Denom())
+	twos := removeFactor(den, 2)
+	fives := removeFactor(den, 5)
+	if den.Cmp(big.NewInt(1)) != 0 {
+		return r.RatString()
+	}
+
+	decimals := twos
+	if fives > decimals {
+		decimals = fives
+	}
+	return r.FloatString(decimals)
+}
+
+// removeFactor n'yi f'ye bölünmez hale gelene kadar böler ve bölme sayısını döner
+func removeFactor(n *big.Int, f int64) int {
+	divisor := big.NewInt(f)
+	quo, rem := new(big.Int), new(big.Int)
+	count := 0
+	for {
+		quo.QuoRem(n, divisor, rem)
+		if rem.Sign() != 0 {
+			return count
+		}
+		n.Set(quo)
+		count++
+	}
+}
+
+// MarshalJSON tutarı kayıpsız string olarak yazar ("12.345")
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.exactString())
+}
+
+// UnmarshalJSON tutarı string ("12.345", "12,345") veya sayı olarak okur.
+// null tutarı değiştirmez (sıfır değer kalır).
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("geçersiz tutar: %s", string(data))
+		}
+		s = n.String()
+	}
+
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// CalculatePriceWithoutVATExact KDV dahil fiyattan KDV hariç fiyatı kesin hesaplar
+func CalculatePriceWithoutVATExact(priceWithVAT Money, vatRate int) Money {
+	return moneyFromRat(new(big.Rat).Quo(priceWithVAT.value(), big.NewRat(int64(100+vatRate), 100)))
+}
+
+// CalculatePriceWithVATExact KDV hariç fiyattan KDV dahil fiyatı kesin hesaplar
+func CalculatePriceWithVATExact(priceWithoutVAT Money, vatRate int) Money {
+	return priceWithoutVAT.MulRate(100 + vatRate)
+}
+
+// CalculateVATAmountExact KDV tutarını kesin hesaplar
+func CalculateVATAmountExact(priceWithoutVAT Money, vatRate int) Money {
+	return priceWithoutVAT.MulRate(vatRate)
+}
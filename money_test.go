@@ -0,0 +1,123 @@
+package nettefatura_test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/vahaponur/nettefatura"
+)
+
+func TestCalculatePriceWithoutVAT(t *testing.T) {
+	tests := []struct {
+		gross   float64
+		rate    int
+		exact   float64
+		rounded float64
+	}{
+		{120, 20, 100, 100},
+		{118, 18, 100, 100},
+		{110, 10, 100, 100},
+		{108, 8, 100, 100},
+		{101, 1, 100, 100},
+		{100, 20, 83.333333, 83.33},
+		{100, 18, 84.745763, 84.75},
+		{999.99, 20, 833.325, 833.33}, // float64 ile 833.32'ye yuvarlanırdı
+		{19.99, 10, 18.172727, 18.17},
+		{33.33, 18, 28.245763, 28.25},
+		{0.1, 8, 0.092593, 0.09},
+		{1, 1, 0.990099, 0.99},
+		{0, 20, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := nettefatura.CalculatePriceWithoutVAT(tt.gross, tt.rate); math.Abs(got-tt.exact) > 1e-6 {
+			t.Errorf("CalculatePriceWithoutVAT(%v, %d) = %v, beklenen %v", tt.gross, tt.rate, got, tt.exact)
+		}
+		if got := nettefatura.CalculatePriceWithoutVATRounded(tt.gross, tt.rate); got != tt.rounded {
+			t.Errorf("CalculatePriceWithoutVATRounded(%v, %d) = %v, beklenen %v", tt.gross, tt.rate, got, tt.rounded)
+		}
+	}
+}
+
+func TestCalculatePriceWithVAT(t *testing.T) {
+	tests := []struct {
+		net          float64
+		rate         int
+		vat, gross   float64
+		vatRounded   float64
+		grossRounded float64
+	}{
+		{100, 20, 20, 120, 20, 120},
+		{100, 1, 1, 101, 1, 101},
+		{0.1, 18, 0.018, 0.118, 0.02, 0.12},
+		{19.99, 8, 1.5992, 21.5892, 1.6, 21.59},
+		{33.33, 1, 0.3333, 33.6633, 0.33, 33.66},
+		{0.05, 10, 0.005, 0.055, 0.01, 0.06},
+		{2.675, 20, 0.535, 3.21, 0.54, 3.21},
+		{1234.56, 20, 246.912, 1481.472, 246.91, 1481.47},
+	}
+
+	for _, tt := range tests {
+		if got := nettefatura.CalculateVATAmount(tt.net, tt.rate); got != tt.vat {
+			t.Errorf("CalculateVATAmount(%v, %d) = %v, beklenen %v", tt.net, tt.rate, got, tt.vat)
+		}
+		if got := nettefatura.CalculatePriceWithVAT(tt.net, tt.rate); got != tt.gross {
+			t.Errorf("CalculatePriceWithVAT(%v, %d) = %v, beklenen %v", tt.net, tt.rate, got, tt.gross)
+		}
+		if got := nettefatura.CalculateVATAmountRounded(tt.net, tt.rate); got != tt.vatRounded {
+			t.Errorf("CalculateVATAmountRounded(%v, %d) = %v, beklenen %v", tt.net, tt.rate, got, tt.vatRounded)
+		}
+		if got := nettefatura.CalculatePriceWithVATRounded(tt.net, tt.rate); got != tt.grossRounded {
+			t.Errorf("CalculatePriceWithVATRounded(%v, %d) = %v, beklenen %v", tt.net, tt.rate, got, tt.grossRounded)
+		}
+	}
+}
+
+func TestVATRoundTrip(t *testing.T) {
+	for _, rate := range []int{1, 8, 10, 18, 20} {
+		for _, net := range []float64{0.01, 1, 9.99, 100, 1234.56} {
+			gross := nettefatura.CalculatePriceWithVAT(net, rate)
+			if got := nettefatura.CalculatePriceWithoutVAT(gross, rate); got != net {
+				t.Errorf("%%%d: %v -> %v -> %v", rate, net, gross, got)
+			}
+		}
+	}
+}
+
+func TestMoneyJSONNull(t *testing.T) {
+	var product nettefatura.Product
+	if err := json.Unmarshal([]byte(`{"name":"Hizmet","exact_price":null}`), &product); err != nil {
+		t.Fatalf("null tutar okunamadı: %v", err)
+	}
+	if !product.ExactPrice.IsZero() {
+		t.Errorf("ExactPrice = %s, beklenen sıfır", product.ExactPrice)
+	}
+
+	// Sıfır tutar yazılmaz, tekrar okunduğunda sıfır kalır
+	data, err := json.Marshal(product)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if strings.Contains(string(data), "exact_price") {
+		t.Errorf("sıfır ExactPrice yazıldı: %s", data)
+	}
+	var decoded nettefatura.Product
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.ExactPrice.IsZero() {
+		t.Errorf("tekrar okuma = %s, %v", decoded.ExactPrice, err)
+	}
+
+	// Verilen tutar kayıpsız korunur
+	product.ExactPrice = nettefatura.NewMoney(12.345)
+	data, _ = json.Marshal(product)
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.ExactPrice.Cmp(product.ExactPrice) != 0 {
+		t.Errorf("tekrar okuma = %s, %v; beklenen 12.345", decoded.ExactPrice, err)
+	}
+
+	// Mevcut değer null ile değişmez
+	money := nettefatura.NewMoney(5)
+	if err := json.Unmarshal([]byte("null"), &money); err != nil || money.Float64() != 5 {
+		t.Errorf("null sonrası = %s, %v", money, err)
+	}
+}
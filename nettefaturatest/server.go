@@ -0,0 +1,567 @@
+// Package nettefaturatest NetteFatura portalını taklit eden test sunucusu sağlar.
+// Gerçek portala bağlanmadan client'ın oluşturduğu istekleri test etmek için kullanılır:
+//
+//	srv := nettefaturatest.NewServer()
+//	defer srv.Close()
+//
+//	client, _ := srv.NewClient("1")
+//	_ = client.Login("1111111111", "secret")
+//	invoiceNo, _ := client.CreateInvoice(invoice)
+//	form := srv.LastRequest("/Invoice/Create").Form
+package nettefaturatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vahaponur/nettefatura"
+)
+
+// DefaultToken sahte sayfalarda sunulan CSRF token
+const DefaultToken = "test-request-verification-token"
+
+// RecordedAttachment /Invoice/UploadAttachment ile yüklenen ek belge
+type RecordedAttachment struct {
+	InvoiceID   string
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// RecordedRequest sunucuya gelen istek
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Form   url.Values
+}
+
+// Server sahte NetteFatura sunucusu
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []RecordedRequest
+
+	// Token sahte sayfalarda sunulan ve POST'larda beklenen CSRF token
+	Token string
+	// RecipientCreateResponse /Recipient/Create JSON yanıtı
+	RecipientCreateResponse string
+	// InvoiceCreateResponse /Invoice/Create ham yanıtı
+	InvoiceCreateResponse string
+	// DraftSaveResponse /Invoice/SaveDraft ham yanıtı
+	DraftSaveResponse string
+	// ApproveDraftResponse /Invoice/ApproveDraft JSON yanıtı
+	ApproveDraftResponse string
+	// Recipients /Recipient/GetRecipientList ile dönen müşteriler
+	Recipients []nettefatura.RecipientListItem
+	// Invoices /Invoice/GetInvoiceList ile dönen faturalar
+	Invoices []nettefatura.InvoiceListItem
+	// InvoiceDetails fatura ID'sine göre /Invoice/GetInvoiceDetail JSON yanıtları, yoksa 404
+	InvoiceDetails map[string]string
+	// RemainingQuota /Company/GetRemainingCredit ile dönen kalan kontör
+	RemainingQuota int
+	// Companies ana sayfadaki firma seçiminde listelenen firmalar
+	Companies []nettefatura.Company
+	// Attachments /Invoice/UploadAttachment ile yüklenen ek belgeler
+	Attachments []RecordedAttachment
+	// CaptchaRequired verilirse /Account/Login portal gibi CAPTCHA sayfası döner
+	CaptchaRequired bool
+	// EInvoiceUsers VKN/TCKN'ye göre /Recipient/CheckGibUser ile dönen mükellefler (yoksa mükellef değil)
+	EInvoiceUsers map[string]nettefatura.MukellefInfo
+	// CheckGibUserStatus verilirse /Recipient/CheckGibUser bu status ile HTML hata sayfası döner
+	CheckGibUserStatus int
+	// Locations /Recipient/GetIlList ve /Recipient/GetIlceList ile dönen il/ilçeler (nil = gömülü veri)
+	Locations *nettefatura.IlIlceData
+}
+
+// NewServer varsayılan yanıtlarla sahte sunucuyu başlatır
+func NewServer() *Server {
+	s := &Server{
+		Token:                   DefaultToken,
+		RecipientCreateResponse: `{"IdAlici":1001}`,
+		InvoiceCreateResponse:   `"TST2024000000001"`,
+		DraftSaveResponse:       `"5001"`,
+		ApproveDraftResponse:    `{"Success":true,"InvoiceNumber":"TST2024000000002","ETTN":"00000000-0000-0000-0000-000000000002"}`,
+		RemainingQuota:          1000,
+		Companies:               []nettefatura.Company{{ID: "1", Name: "Test Firma", TaxNumber: "1111111111"}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/account/login", s.handleTokenPage)
+	mux.HandleFunc("/Invoice/CreateQuick", s.handleTokenPage)
+	mux.HandleFunc("/Product/Index", s.handleTokenPage)
+	mux.HandleFunc("/Account/Login", s.handleLogin)
+	mux.HandleFunc("/Recipient/Create", s.handleRecipientCreate)
+	mux.HandleFunc("/Recipient/GetRecipientList", s.handleRecipientList)
+	mux.HandleFunc("/Recipient/Detail", s.handleRecipientDetail)
+	mux.HandleFunc("/Recipient/GetRecipient", s.handleRecipientJSON)
+	mux.HandleFunc("/Recipient/GetIlList", s.handleCityList)
+	mux.HandleFunc("/Recipient/GetIlceList", s.handleDistrictList)
+	mux.HandleFunc("/Invoice/Create", s.handleInvoiceCreate)
+	mux.HandleFunc("/Invoice/SaveDraft", s.handleDraftSave)
+	mux.HandleFunc("/Invoice/ApproveDraft", s.handleApproveDraft)
+	mux.HandleFunc("/Invoice/GetInvoiceList", s.handleInvoiceList)
+	mux.HandleFunc("/Invoice/GetInvoiceDetail", s.handleInvoiceDetail)
+	mux.HandleFunc("/Invoice/UploadAttachment", s.handleUploadAttachment)
+	mux.HandleFunc("/Recipient/CheckGibUser", s.handleCheckGibUser)
+	mux.HandleFunc("/Company/GetRemainingCredit", s.handleRemainingCredit)
+	mux.HandleFunc("/", s.handleHome)
+
+	s.Server = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// NewClient sahte sunucuya bağlı client oluşturur
+func (s *Server) NewClient(companyID string, options ...nettefatura.Option) (*nettefatura.Client, error) {
+	options = append([]nettefatura.Option{nettefatura.WithBaseURL(s.URL)}, options...)
+	return nettefatura.NewClient(companyID, options...)
+}
+
+// Requests sunucuya gelen tüm istekleri sırasıyla döner
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// LastRequest verilen yola gelen son isteği döner, yoksa nil
+func (s *Server) LastRequest(path string) *RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if s.requests[i].Path == path {
+			req := s.requests[i]
+			return &req
+		}
+	}
+	return nil
+}
+
+// record gelen istekleri kaydeder
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Dosya yüklemelerinde form alanları multipart gövdeden okunur
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			r.ParseMultipartForm(32 << 20)
+		} else {
+			r.ParseForm()
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, RecordedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Form:   r.PostForm,
+		})
+		s.mu.Unlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkToken POST isteğindeki CSRF token'ı doğrular
+func (s *Server) checkToken(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if r.PostForm.Get("__RequestVerificationToken") != s.Token {
+		http.Error(w, "The required anti-forgery form field \"__RequestVerificationToken\" is not present.", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// handleTokenPage CSRF token içeren sahte sayfa
+func (s *Server) handleTokenPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<html><body><form method="post">
+<input name="__RequestVerificationToken" type="hidden" value="%s" />
+</form></body></html>`, s.Token)
+}
+
+// handleHome giriş sonrası yönlendirilen ana sayfa
+func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.Lock()
+	var options strings.Builder
+	for _, company := range s.Companies {
+		fmt.Fprintf(&options, `<option value="%s">%s (%s)</option>`,
+			html.EscapeString(company.ID), html.EscapeString(company.Name), html.EscapeString(company.TaxNumber))
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<html><body>NetteFatura
+<select id="CompanyId" name="CompanyId">%s</select>
+</body></html>`, options.String())
+}
+
+// handleLogin giriş isteğini kabul edip oturum cookie'si verir.
+// Bilgiler eksikse portal gibi giriş formunu hata mesajıyla 200 döner.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !s.checkToken(w, r) {
+		return
+	}
+	s.mu.Lock()
+	captcha := s.CaptchaRequired
+	s.mu.Unlock()
+	if captcha {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<html><body><form method="post" action="/Account/Login">
+<input name="__RequestVerificationToken" type="hidden" value="%s" />
+<div class="g-recaptcha" data-sitekey="test-captcha-site-key"></div>
+</form></body></html>`, s.Token)
+		return
+	}
+
+	if r.PostForm.Get("VknTckn") == "" || r.PostForm.Get("Password") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<html><body><form method="post" action="/Account/Login">
+<div class="validation-summary-errors"><ul><li>Kullanıcı adı veya şifre hatalı.</li></ul></div>
+<input name="__RequestVerificationToken" type="hidden" value="%s" />
+<input id="VknTckn" name="VknTckn" type="text" value="" />
+<input id="Password" name="Password" type="password" />
+</form></body></html>`, s.Token)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: ".ASPXAUTH", Value: "test-session", Path: "/"})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// handleRecipientCreate müşteri oluşturma yanıtı
+func (s *Server) handleRecipientCreate(w http.ResponseWriter, r *http.Request) {
+	if !s.checkToken(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, s.RecipientCreateResponse)
+}
+
+// handleInvoiceCreate fatura oluşturma yanıtı
+func (s *Server) handleInvoiceCreate(w http.ResponseWriter, r *http.Request) {
+	if !s.checkToken(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, s.InvoiceCreateResponse)
+}
+
+// handleDraftSave taslak kaydetme yanıtı
+func (s *Server) handleDraftSave(w http.ResponseWriter, r *http.Request) {
+	if !s.checkToken(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, s.DraftSaveResponse)
+}
+
+// handleApproveDraft taslak onay yanıtı
+func (s *Server) handleApproveDraft(w http.ResponseWriter, r *http.Request) {
+	if !s.checkToken(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, s.ApproveDraftResponse)
+}
+
+// handleRecipientList DataTables formatında sayfalı müşteri listesi
+func (s *Server) handleRecipientList(w http.ResponseWriter, r *http.Request) {
+	var start, length int
+	fmt.Sscanf(r.PostForm.Get("start"), "%d", &start)
+	fmt.Sscanf(r.PostForm.Get("length"), "%d", &length)
+
+	s.mu.Lock()
+	recipients := append([]nettefatura.RecipientListItem(nil), s.Recipients...)
+	s.mu.Unlock()
+
+	// Alıcı tipi filtresi (0 = tümü)
+	if recipientType := r.PostForm.Get("AliciTipi"); recipientType != "" && recipientType != "0" {
+		filtered := recipients[:0]
+		for _, recipient := range recipients {
+			if fmt.Sprintf("%d", recipient.AliciTipi) == recipientType {
+				filtered = append(filtered, recipient)
+			}
+		}
+		recipients = filtered
+	}
+
+	sortRecipients(recipients, r.PostForm.Get("order[0][column]"), r.PostForm.Get("order[0][dir]"))
+
+	page := []nettefatura.RecipientListItem{}
+	if start < len(recipients) {
+		end := start + length
+		if length <= 0 || end > len(recipients) {
+			end = len(recipients)
+		}
+		page = recipients[start:end]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(nettefatura.RecipientListResponse{
+		Draw:            1,
+		RecordsTotal:    len(recipients),
+		RecordsFiltered: len(recipients),
+		Data:            page,
+	})
+}
+
+// sortRecipients müşterileri DataTables order[0] alanlarına göre sıralar
+func sortRecipients(recipients []nettefatura.RecipientListItem, column, dir string) {
+	var less func(a, b nettefatura.RecipientListItem) bool
+	switch column {
+	case "0":
+		less = func(a, b nettefatura.RecipientListItem) bool { return a.IdAlici < b.IdAlici }
+	case "2":
+		less = func(a, b nettefatura.RecipientListItem) bool { return a.AliciAdi < b.AliciAdi }
+	case "3":
+		less = func(a, b nettefatura.RecipientListItem) bool { return a.Vnktckn < b.Vnktckn }
+	case "4":
+		less = func(a, b nettefatura.RecipientListItem) bool { return a.StateName < b.StateName }
+	default:
+		return
+	}
+
+	sort.SliceStable(recipients, func(i, j int) bool {
+		if dir == "desc" {
+			return less(recipients[j], recipients[i])
+		}
+		return less(recipients[i], recipients[j])
+	})
+}
+
+// selectListItem portalın açılır liste öğesi
+type selectListItem struct {
+	Value string
+	Text  string
+}
+
+// handleCityList il açılır listesini döner
+func (s *Server) handleCityList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	locations := s.Locations
+	s.mu.Unlock()
+
+	cities := nettefatura.GetCities()
+	if locations != nil {
+		cities = locations.Cities
+	}
+
+	items := []selectListItem{{Value: "", Text: "Seçiniz"}}
+	for _, city := range cities {
+		items = append(items, selectListItem{Value: city.ID, Text: city.Name})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// handleDistrictList ilin ilçe açılır listesini döner
+func (s *Server) handleDistrictList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	locations := s.Locations
+	s.mu.Unlock()
+
+	cityID := r.URL.Query().Get("ilId")
+	districts := nettefatura.GetDistricts(cityID)
+	if locations != nil {
+		districts = locations.Districts[cityID]
+	}
+
+	items := []selectListItem{{Value: "", Text: "Seçiniz"}}
+	for _, district := range districts {
+		items = append(items, selectListItem{Value: fmt.Sprintf("%d", district.ID), Text: district.Name})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// handleUploadAttachment ek belgeyi kaydeder
+func (s *Server) handleUploadAttachment(w http.ResponseWriter, r *http.Request) {
+	if !s.checkToken(w, r) {
+		return
+	}
+
+	file, header, err := r.FormFile("File")
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"Success":false,"ErrorMessage":"Dosya bulunamadı."}`)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.Attachments = append(s.Attachments, RecordedAttachment{
+		InvoiceID:   r.PostForm.Get("InvoiceId"),
+		Filename:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Content:     content,
+	})
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"Success":true}`)
+}
+
+// handleCheckGibUser EInvoiceUsers'a göre mükellef sorgusunu yanıtlar
+func (s *Server) handleCheckGibUser(w http.ResponseWriter, r *http.Request) {
+	taxNumber := r.URL.Query().Get("vknTckn")
+
+	s.mu.Lock()
+	status := s.CheckGibUserStatus
+	info, ok := s.EInvoiceUsers[taxNumber]
+	s.mu.Unlock()
+
+	if status != 0 {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		fmt.Fprint(w, `<html><body>Sunucu hatası</body></html>`)
+		return
+	}
+	if !ok {
+		info = nettefatura.MukellefInfo{VknTckn: taxNumber}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// handleRemainingCredit kalan kontörü döner
+func (s *Server) handleRemainingCredit(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	quota := s.RemainingQuota
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"RemainingCredit":%d}`, quota)
+}
+
+// handleInvoiceDetail InvoiceDetails'teki fatura ayrıntısını döner
+func (s *Server) handleInvoiceDetail(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	detail, ok := s.InvoiceDetails[r.URL.Query().Get("invoiceId")]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, detail)
+}
+
+// handleInvoiceList arama metnini içeren ve müşteri filtresine uyan faturaları DataTables formatında döner
+func (s *Server) handleInvoiceList(w http.ResponseWriter, r *http.Request) {
+	search := r.PostForm.Get("search[value]")
+	recipientFilter := r.PostForm.Get("RecipientIdFilter")
+
+	s.mu.Lock()
+	invoices := []nettefatura.InvoiceListItem{}
+	for _, invoice := range s.Invoices {
+		if recipientFilter != "" && fmt.Sprintf("%d", invoice.RecipientId) != recipientFilter {
+			continue
+		}
+		if search == "" || strings.Contains(invoice.OrderNumber, search) || strings.Contains(invoice.InvoiceNumber, search) {
+			invoices = append(invoices, invoice)
+		}
+	}
+	total := len(s.Invoices)
+	s.mu.Unlock()
+
+	var start, length int
+	fmt.Sscanf(r.PostForm.Get("start"), "%d", &start)
+	fmt.Sscanf(r.PostForm.Get("length"), "%d", &length)
+
+	page := []nettefatura.InvoiceListItem{}
+	if start < len(invoices) {
+		end := start + length
+		if length <= 0 || end > len(invoices) {
+			end = len(invoices)
+		}
+		page = invoices[start:end]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(nettefatura.InvoiceListResponse{
+		Draw:            1,
+		RecordsTotal:    total,
+		RecordsFiltered: len(invoices),
+		Data:            page,
+	})
+}
+
+// handleRecipientJSON Recipients içindeki müşteriyi JSON olarak döner
+func (s *Server) handleRecipientJSON(w http.ResponseWriter, r *http.Request) {
+	var id int
+	fmt.Sscanf(r.URL.Query().Get("RecipientId"), "%d", &id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, recipient := range s.Recipients {
+		if recipient.IdAlici == id {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(recipient)
+			return
+		}
+	}
+
+	http.NotFound(w, r)
+}
+
+// handleRecipientDetail Recipients içindeki müşterinin detay sayfası
+func (s *Server) handleRecipientDetail(w http.ResponseWriter, r *http.Request) {
+	var id int
+	fmt.Sscanf(r.URL.Query().Get("RecipientId"), "%d", &id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, recipient := range s.Recipients {
+		if recipient.IdAlici != id {
+			continue
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<form>
+<input id="AliciAdi" value="%s" />
+<input id="VknTckn" value="%s" />
+<input id="Email" value="%s" />
+<input id="Telefon" value="%s" />
+<input id="SokakAdi" value="%s" />
+<input id="PostaKodu" value="%s" />
+<input id="BinaNo" value="%s" />
+<select id="CityId"><option value="%d" selected>%s</option></select>
+<select id="AliciTipi"><option value="%d" selected="selected"></option></select>
+<select id="FaturaGonderimSekli"><option value="%d" selected="selected"></option></select>
+</form>`,
+			html.EscapeString(recipient.AliciAdi), html.EscapeString(recipient.Vnktckn),
+			html.EscapeString(recipient.Email), html.EscapeString(recipient.Telefon),
+			html.EscapeString(recipient.SokakAdi), html.EscapeString(recipient.PostaKodu),
+			html.EscapeString(recipient.BinaNo), recipient.IdIl, html.EscapeString(recipient.IlAdi),
+			recipient.AliciTipi, recipient.FaturaGonderimSekli)
+		return
+	}
+
+	http.NotFound(w, r)
+}
@@ -0,0 +1,61 @@
+package nettefatura
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInvoiceNotesLimit(t *testing.T) {
+	notes := make([]string, 25)
+	for i := range notes {
+		notes[i] = fmt.Sprintf("Not %d", i+1)
+	}
+
+	// Varsayılan olarak sınır yoktur
+	client, err := NewClient("1")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	got, err := client.invoiceNotes(Invoice{Notes: notes})
+	if err != nil || len(got) != len(notes) {
+		t.Errorf("varsayılan sınır uygulandı: %d not, %v", len(got), err)
+	}
+
+	// Sınır varsayılan notlar dahil, boş notlar atıldıktan sonra uygulanır
+	limited, err := NewClient("1", WithMaxNotes(2), WithDefaultNotes([]string{"İmza"}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := limited.invoiceNotes(Invoice{Notes: []string{"Teşekkürler", " "}}); err != nil {
+		t.Errorf("sınır içindeki notlar reddedildi: %v", err)
+	}
+	if _, err := limited.invoiceNotes(Invoice{Notes: []string{"Teşekkürler", "Vade 30 gün"}}); err == nil {
+		t.Error("sınırı aşan notlar kabul edildi")
+	}
+}
+
+func TestInvoiceNotesLength(t *testing.T) {
+	long := strings.Repeat("ş", 600)
+
+	// Uzunluk sınırı isteğe bağlıdır
+	client, err := NewClient("1")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.invoiceNotes(Invoice{Notes: []string{long}}); err != nil {
+		t.Errorf("varsayılan uzunluk sınırı uygulandı: %v", err)
+	}
+
+	// Karakter (rune) bazında sayılır
+	limited, err := NewClient("1", WithMaxNoteLength(600))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := limited.invoiceNotes(Invoice{Notes: []string{long}}); err != nil {
+		t.Errorf("sınırdaki not reddedildi: %v", err)
+	}
+	if _, err := limited.invoiceNotes(Invoice{Notes: []string{"Kısa", long + "ş"}}); err == nil || !strings.Contains(err.Error(), "2. not") {
+		t.Errorf("hata = %v, beklenen 2. not uzunluk hatası", err)
+	}
+}
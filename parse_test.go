@@ -0,0 +1,30 @@
+package nettefatura
+
+import "testing"
+
+func TestSelectedOptionValue(t *testing.T) {
+	page := `<form>
+<select data-id="AliciTipi"><option value="9" selected>Yanlış</option></select>
+<select class="form-control" id="AliciTipi" name="AliciTipi">
+	<option value="1">Bireysel</option>
+	<option selected="selected" value=" 2 ">Kurumsal</option>
+</select>
+<SELECT ID="FaturaGonderimSekli"><OPTION VALUE="1">Elektronik</OPTION><OPTION VALUE="2" SELECTED>Kağıt</OPTION></SELECT>
+<select id="CityId"><option value="34">İstanbul</option></select>
+</form>`
+
+	tests := []struct {
+		id, want string
+	}{
+		{"AliciTipi", "2"},
+		{"FaturaGonderimSekli", "2"},
+		{"CityId", ""},
+		{"IdIlce", ""},
+	}
+
+	for _, tt := range tests {
+		if got := selectedOptionValue(page, tt.id); got != tt.want {
+			t.Errorf("selectedOptionValue(%q) = %q, beklenen %q", tt.id, got, tt.want)
+		}
+	}
+}
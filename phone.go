@@ -0,0 +1,51 @@
+package nettefatura
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizePhone telefon numarasını portalın beklediği 10 haneli ulusal biçime çevirir
+// ("0 (532) 123 45 67", "+90 532 123 4567" -> "5321234567").
+// Yalnızca Türkiye cep (5xx), sabit hat (2xx, 3xx, 4xx) ve 850 numaraları kabul edilir.
+func NormalizePhone(phone string) (string, error) {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '(' || r == ')' || r == '-' || r == '.' || r == '/':
+		default:
+			return "", fmt.Errorf("geçersiz telefon numarası %q: beklenmeyen karakter %q", phone, r)
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case len(number) == 14 && strings.HasPrefix(number, "0090"):
+		number = number[4:]
+	case len(number) == 13 && strings.HasPrefix(number, "900"):
+		number = number[3:]
+	case len(number) == 12 && strings.HasPrefix(number, "90"):
+		number = number[2:]
+	case len(number) == 11 && strings.HasPrefix(number, "0"):
+		number = number[1:]
+	}
+
+	if len(number) != 10 {
+		return "", fmt.Errorf("geçersiz telefon numarası %q: 10 haneli olmalıdır (alan kodu dahil)", phone)
+	}
+
+	switch number[0] {
+	case '2', '3', '4', '5':
+	case '8':
+		if !strings.HasPrefix(number, "850") {
+			return "", fmt.Errorf("geçersiz telefon numarası %q: bilinmeyen alan kodu %s", phone, number[:3])
+		}
+	default:
+		return "", fmt.Errorf("geçersiz telefon numarası %q: bilinmeyen alan kodu %s", phone, number[:3])
+	}
+
+	return number, nil
+}
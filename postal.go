@@ -0,0 +1,96 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// PostalCodeEntry posta kodunun ait olduğu il ve ilçe adı
+type PostalCodeEntry struct {
+	Code     string `json:"code"`
+	City     string `json:"city"`
+	District string `json:"district"`
+}
+
+// postalCodeData kullanımdaki posta kodu -> ilçe veri seti
+var postalCodeData atomic.Pointer[map[string]PostalCodeEntry]
+
+// SetPostalCodeData posta kodlarını ilçelere eşleyen veri setini yükler.
+// Veri doğrulanır ve kopyalanır; geçersizse mevcut veri değişmez.
+func SetPostalCodeData(entries []PostalCodeEntry) error {
+	data := make(map[string]PostalCodeEntry, len(entries))
+	for _, entry := range entries {
+		entry.Code = strings.TrimSpace(entry.Code)
+		if !isDigits(entry.Code, 5) {
+			return fmt.Errorf("geçersiz posta kodu: %q", entry.Code)
+		}
+		if strings.TrimSpace(entry.City) == "" || strings.TrimSpace(entry.District) == "" {
+			return fmt.Errorf("posta kodu %s: il ve ilçe adı zorunludur", entry.Code)
+		}
+		if _, exists := data[entry.Code]; exists {
+			return fmt.Errorf("tekrarlanan posta kodu: %s", entry.Code)
+		}
+		data[entry.Code] = entry
+	}
+
+	postalCodeData.Store(&data)
+	return nil
+}
+
+// LoadPostalCodeDataFromFile posta kodu veri setini JSON dosyasından yükler.
+// Dosya [{"code": "34710", "city": "İstanbul", "district": "Kadıköy"}, ...] biçiminde olmalıdır.
+func LoadPostalCodeDataFromFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("posta kodu dosyası okunamadı: %w", err)
+	}
+
+	var entries []PostalCodeEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	return SetPostalCodeData(entries)
+}
+
+// GetCityByPostalCode posta kodunun ilk iki hanesi olan plaka kodundan ili bulur.
+// İlçe bilgisi vermez; ilçe için GetLocationByPostalCode kullanılır.
+func GetCityByPostalCode(code string) (City, bool) {
+	code = strings.TrimSpace(code)
+	if !isDigits(code, 5) {
+		return City{}, false
+	}
+	return GetCityByPlateCode(parseIntOrZero(code[:2]))
+}
+
+// GetLocationByPostalCode posta kodundan il ve ilçeyi bulur.
+// Posta kodu veri seti paketle gelmez; SetPostalCodeData veya LoadPostalCodeDataFromFile
+// ile yüklenmemişse, kod veri setinde yoksa veya il/ilçe il-ilçe veri setinde
+// bulunamazsa false döner.
+func GetLocationByPostalCode(code string) (City, District, bool) {
+	data := postalCodeData.Load()
+	if data == nil {
+		return City{}, District{}, false
+	}
+
+	entry, ok := (*data)[strings.TrimSpace(code)]
+	if !ok {
+		return City{}, District{}, false
+	}
+
+	// Veri setindeki il, kodun plaka önekiyle uyuşmalıdır
+	city, ok := GetCityByPostalCode(entry.Code)
+	if !ok || normalizeString(entry.City) != normalizeString(city.Name) {
+		return City{}, District{}, false
+	}
+
+	districtID := GetDistrictID(city.ID, entry.District)
+	if districtID == -1 {
+		return City{}, District{}, false
+	}
+
+	return city, District{ID: districtID, Name: GetDistrictName(city.ID, districtID)}, true
+}
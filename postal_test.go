@@ -0,0 +1,61 @@
+package nettefatura
+
+import "testing"
+
+func TestGetLocationByPostalCode(t *testing.T) {
+	t.Cleanup(func() { postalCodeData.Store(nil) })
+
+	// Veri seti yüklenmeden ilçe (ve il) tahmin edilmez
+	if _, _, ok := GetLocationByPostalCode("34710"); ok {
+		t.Fatal("veri seti yüklenmeden konum bulundu")
+	}
+
+	err := SetPostalCodeData([]PostalCodeEntry{
+		{Code: "34710", City: "İstanbul", District: "Kadıköy"},
+		{Code: "06420", City: "ANKARA", District: "Çankaya"},
+		{Code: "35000", City: "Ankara", District: "Çankaya"}, // plaka öneki İzmir
+	})
+	if err != nil {
+		t.Fatalf("SetPostalCodeData: %v", err)
+	}
+
+	city, district, ok := GetLocationByPostalCode(" 34710 ")
+	if !ok || city.ID != GetCityID("İstanbul") || district.ID != GetDistrictIDByNames("İstanbul", "Kadıköy") {
+		t.Errorf("34710 = %+v %+v %v", city, district, ok)
+	}
+	if _, district, ok := GetLocationByPostalCode("06420"); !ok || district.Name == "" {
+		t.Errorf("06420 = %+v %v", district, ok)
+	}
+	for _, code := range []string{"35000", "34000", "abc"} {
+		if _, _, ok := GetLocationByPostalCode(code); ok {
+			t.Errorf("%s için konum bulundu", code)
+		}
+	}
+}
+
+func TestGetCityByPostalCode(t *testing.T) {
+	city, ok := GetCityByPostalCode("34710")
+	if !ok || city.ID != GetCityID("İstanbul") {
+		t.Errorf("34710 = %+v %v, beklenen İstanbul", city, ok)
+	}
+	for _, code := range []string{"", "3471", "99000", "00100", "3471a"} {
+		if _, ok := GetCityByPostalCode(code); ok {
+			t.Errorf("%q için il bulundu", code)
+		}
+	}
+}
+
+func TestSetPostalCodeDataValidation(t *testing.T) {
+	t.Cleanup(func() { postalCodeData.Store(nil) })
+
+	invalid := [][]PostalCodeEntry{
+		{{Code: "3471", City: "İstanbul", District: "Kadıköy"}},
+		{{Code: "34710", City: "", District: "Kadıköy"}},
+		{{Code: "34710", City: "İstanbul", District: "Kadıköy"}, {Code: "34710", City: "İstanbul", District: "Üsküdar"}},
+	}
+	for _, entries := range invalid {
+		if err := SetPostalCodeData(entries); err == nil {
+			t.Errorf("geçersiz veri kabul edildi: %+v", entries)
+		}
+	}
+}
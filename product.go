@@ -0,0 +1,124 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ProductCatalogItem ürün kataloğu öğesi
+type ProductCatalogItem struct {
+	ProductId     int     `json:"ProductId"`
+	ProductName   string  `json:"ProductName"`
+	ProductCode   string  `json:"ProductCode"`
+	UnitPrice     float64 `json:"UnitPrice"`
+	VatRate       int     `json:"VatRate"`
+	MeasureUnitId int     `json:"MeasureUnitId"`
+	IdFirma       int     `json:"IdFirma"`
+}
+
+// ProductListResponse ürün listesi API yanıtı
+type ProductListResponse struct {
+	Draw            int                  `json:"draw"`
+	RecordsTotal    int                  `json:"recordsTotal"`
+	RecordsFiltered int                  `json:"recordsFiltered"`
+	Data            []ProductCatalogItem `json:"data"`
+}
+
+// GetProductList ürün kataloğunun tamamını getirir
+func (c *Client) GetProductList() ([]ProductCatalogItem, error) {
+	var products []ProductCatalogItem
+	start := 0
+	length := 200
+
+	for {
+		form := url.Values{
+			"draw":            {"1"},
+			"start":           {fmt.Sprintf("%d", start)},
+			"length":          {fmt.Sprintf("%d", length)},
+			"search[value]":   {""},
+			"search[regex]":   {"false"},
+			"CompanyIdFilter": {c.companyID()},
+		}
+
+		req, err := c.newRequest("POST", c.config.BaseURL+"/Product/GetProductList", strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, fmt.Errorf("request oluşturulamadı: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("ürün listesi isteği başarısız: %w", err)
+		}
+
+		body, err := c.readBody(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("response okunamadı: %w", err)
+		}
+
+		var result ProductListResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("JSON parse hatası: %w", err)
+		}
+
+		products = append(products, result.Data...)
+
+		// Eğer gelen veri sayısı length'ten azsa, tüm veri alındı
+		if len(result.Data) < length {
+			break
+		}
+
+		// Sonraki sayfa
+		start += length
+	}
+
+	return products, nil
+}
+
+// CreateProduct katalogda yeni ürün oluşturur ve ürün ID'sini döner.
+// Ürün adı, KDV hariç birim fiyat ve KDV oranı kullanılır, ölçü birimi client ayarından gelir.
+func (c *Client) CreateProduct(product Product) (string, error) {
+	// Validasyonlar
+	if product.Name == "" {
+		return "", fmt.Errorf("ürün adı zorunludur")
+	}
+
+	form := url.Values{
+		"ProductName":   {product.Name},
+		"UnitPrice":     {fmt.Sprintf("%.2f", product.Price)},
+		"VatRate":       {fmt.Sprintf("%d", product.VATRate)},
+		"MeasureUnitId": {fmt.Sprintf("%d", c.config.MeasureUnit)},
+		"IdFirma":       {c.companyID()},
+	}
+
+	body, err := c.postWithToken("/Product/Index", "/Product/Create", form, "ürün oluşturma")
+	if err != nil {
+		return "", err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	// Hata kontrolü
+	if errorMsg, ok := result["error"].(string); ok && errorMsg != "" {
+		return "", fmt.Errorf("ürün oluşturma hatası: %s", errorMsg)
+	}
+
+	if errorMsg, ok := result["ErrorMessage"].(string); ok && errorMsg != "" {
+		return "", fmt.Errorf("ürün oluşturma hatası: %s", errorMsg)
+	}
+
+	// Başarılı - ID'yi al
+	if productID, ok := result["ProductId"].(float64); ok {
+		return fmt.Sprintf("%.0f", productID), nil
+	}
+
+	return "", fmt.Errorf("ürün ID bulunamadı: %s", string(body))
+}
@@ -0,0 +1,83 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInsufficientQuota kalan kontör gönderilecek fatura sayısına yetmiyor
+var ErrInsufficientQuota = errors.New("yetersiz kontör")
+
+// quotaKeys kontör yanıtında kalan kontörü taşıyabilecek alanlar
+var quotaKeys = []string{"RemainingCredit", "KalanKontor", "Kontor", "Remaining"}
+
+// GetRemainingQuota firmanın kalan fatura kontörünü getirir
+func (c *Client) GetRemainingQuota() (int, error) {
+	endpoint := fmt.Sprintf("%s/Company/GetRemainingCredit?companyId=%s", c.config.BaseURL, url.QueryEscape(c.companyID()))
+
+	req, err := c.newRequest("GET", endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("kontör isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	return parseQuota(body)
+}
+
+// parseQuota kontör yanıtını parse eder; yanıt sayı veya kontör alanı içeren nesne olabilir
+func parseQuota(body []byte) (int, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return 0, fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return int(v), nil
+	case map[string]interface{}:
+		for key, field := range v {
+			for _, quotaKey := range quotaKeys {
+				if !strings.EqualFold(key, quotaKey) {
+					continue
+				}
+				if n, ok := field.(float64); ok {
+					return int(n), nil
+				}
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("kontör bilgisi bulunamadı: %s", string(body))
+}
+
+// checkQuota kalan kontörün gönderilecek fatura sayısına yetip yetmediğini kontrol eder
+func (c *Client) checkQuota(needed int) error {
+	if needed == 0 {
+		return nil
+	}
+
+	remaining, err := c.GetRemainingQuota()
+	if err != nil {
+		return fmt.Errorf("kontör kontrolü başarısız: %w", err)
+	}
+	if remaining < needed {
+		return fmt.Errorf("%w: %d fatura için %d kontör kaldı", ErrInsufficientQuota, needed, remaining)
+	}
+
+	return nil
+}
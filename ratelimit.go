@@ -0,0 +1,58 @@
+package nettefatura
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter token bucket hız sınırlayıcı (kapasite 1)
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter saniyede requestsPerSecond isteğe izin veren sınırlayıcı oluşturur
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / requestsPerSecond),
+	}
+}
+
+// reserve bir sonraki istek için beklenmesi gereken süreyi döner
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	return wait
+}
+
+// rateLimitTransport tüm giden istekleri sınırlayıcıdan geçirir
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+// RoundTrip sıradaki isteğe izin verilene kadar bekler
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.limiter.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,90 @@
+package nettefatura
+
+import "time"
+
+// RecurrenceFrequency tekrarlayan fatura sıklığı
+type RecurrenceFrequency int
+
+const (
+	// RecurMonthly her ay (Interval ile her n ayda bir)
+	RecurMonthly RecurrenceFrequency = iota
+	// RecurWeekly her hafta (Interval ile her n haftada bir)
+	RecurWeekly
+)
+
+// RecurringSchedule tekrarlayan fatura takvimi
+type RecurringSchedule struct {
+	Frequency RecurrenceFrequency
+	Interval  int       // Kaç dönemde bir, 0 ise 1
+	Start     time.Time // İlk fatura tarihi (saat bilgisi korunur)
+
+	// DayOfMonth aylık faturanın kesileceği gün (1-31), 0 ise Start'ın günü.
+	// Ay bu günden kısaysa ayın son günü kullanılır (31 -> 28/29 Şubat).
+	DayOfMonth int
+}
+
+// occurrence takvimdeki n. (0'dan başlayan) fatura tarihini hesaplar
+func (s RecurringSchedule) occurrence(n int) time.Time {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	if s.Frequency == RecurWeekly {
+		return s.Start.AddDate(0, 0, 7*n*interval)
+	}
+
+	day := s.DayOfMonth
+	if day <= 0 {
+		day = s.Start.Day()
+	}
+
+	// Ay kayması olmaması için her tarih ayın ilk gününden hesaplanır
+	first := time.Date(s.Start.Year(), s.Start.Month(), 1, s.Start.Hour(), s.Start.Minute(), s.Start.Second(), 0, s.Start.Location())
+	first = first.AddDate(0, n*interval, 0)
+
+	if last := daysInMonth(first); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// daysInMonth tarihin bulunduğu ayın gün sayısını döner
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+// GenerateRecurringInvoices şablon faturadan takvime göre upTo tarihine kadar (dahil)
+// tarihlenmiş kopyalar üretir. Ağ isteği yapmaz; sonuç CreateInvoices'a verilebilir.
+// Şablonda Reference varsa her kopyanın referansına tarih eklenir (SIPARIS-20240131),
+// böylece mükerrer kontrolü kopyaları aynı fatura saymaz. Start boşsa nil döner.
+func GenerateRecurringInvoices(template Invoice, schedule RecurringSchedule, upTo time.Time) []Invoice {
+	if schedule.Start.IsZero() {
+		return nil
+	}
+
+	var invoices []Invoice
+	for n := 0; ; n++ {
+		date := schedule.occurrence(n)
+		if date.After(upTo) {
+			break
+		}
+		// DayOfMonth Start'ın gününden önceyse ilk ay atlanır
+		if date.Before(schedule.Start) {
+			continue
+		}
+
+		invoice := template
+		invoice.Date = date
+		invoice.Products = append([]Product(nil), template.Products...)
+		invoice.Notes = append([]string(nil), template.Notes...)
+		invoice.DispatchList = nil // İrsaliyeler tek bir faturaya bağlanır
+		if template.Reference != "" {
+			invoice.Reference = template.Reference + "-" + date.Format("20060102")
+		}
+
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices
+}
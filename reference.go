@@ -0,0 +1,140 @@
+package nettefatura
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// MeasureUnit portalın ölçü birimi (bkz. WithMeasureUnit)
+type MeasureUnit struct {
+	ID   int    `json:"Id"`
+	Code string `json:"Code"` // UN/ECE kodu (örn. C62)
+	Name string `json:"Name"`
+}
+
+// TaxOffice vergi dairesi (bkz. Customer.TaxOfficeID)
+type TaxOffice struct {
+	ID     int    `json:"Id"`
+	Code   string `json:"Code"`
+	Name   string `json:"Name"`
+	CityID int    `json:"CityId"`
+}
+
+// referenceCache portaldan alınan referans verilerinin client bazlı önbelleği
+type referenceCache struct {
+	mu           sync.Mutex
+	measureUnits []MeasureUnit
+	vatRates     []int
+	taxOffices   map[string][]TaxOffice
+}
+
+// GetMeasureUnits portalda tanımlı ölçü birimlerini getirir.
+// Sonuç client ömrü boyunca önbellekte tutulur.
+func (c *Client) GetMeasureUnits() ([]MeasureUnit, error) {
+	c.reference.mu.Lock()
+	cached := c.reference.measureUnits
+	c.reference.mu.Unlock()
+	if cached != nil {
+		return append([]MeasureUnit(nil), cached...), nil
+	}
+
+	var units []MeasureUnit
+	if err := c.getReferenceData("/Definition/GetMeasureUnits", "ölçü birimi", &units); err != nil {
+		return nil, err
+	}
+
+	c.reference.mu.Lock()
+	c.reference.measureUnits = units
+	c.reference.mu.Unlock()
+
+	return append([]MeasureUnit(nil), units...), nil
+}
+
+// GetVATRates portalda geçerli KDV oranlarını (%) getirir.
+// Sonuç önbellekte tutulur; WithAllowedVATRates ile birlikte kullanılabilir.
+func (c *Client) GetVATRates() ([]int, error) {
+	c.reference.mu.Lock()
+	cached := c.reference.vatRates
+	c.reference.mu.Unlock()
+	if cached != nil {
+		return append([]int(nil), cached...), nil
+	}
+
+	var rates []int
+	if err := c.getReferenceData("/Definition/GetVatRates", "KDV oranı", &rates); err != nil {
+		return nil, err
+	}
+
+	c.reference.mu.Lock()
+	c.reference.vatRates = rates
+	c.reference.mu.Unlock()
+
+	return append([]int(nil), rates...), nil
+}
+
+// GetTaxOffices ildeki vergi dairelerini getirir. Sonuç il bazında önbellekte tutulur.
+func (c *Client) GetTaxOffices(cityID string) ([]TaxOffice, error) {
+	if cityID == "" {
+		return nil, fmt.Errorf("il ID gerekli")
+	}
+
+	c.reference.mu.Lock()
+	cached, ok := c.reference.taxOffices[cityID]
+	c.reference.mu.Unlock()
+	if ok {
+		return append([]TaxOffice(nil), cached...), nil
+	}
+
+	var offices []TaxOffice
+	path := "/Definition/GetTaxOffices?cityId=" + url.QueryEscape(cityID)
+	if err := c.getReferenceData(path, "vergi dairesi", &offices); err != nil {
+		return nil, err
+	}
+
+	c.reference.mu.Lock()
+	if c.reference.taxOffices == nil {
+		c.reference.taxOffices = map[string][]TaxOffice{}
+	}
+	c.reference.taxOffices[cityID] = offices
+	c.reference.mu.Unlock()
+
+	return append([]TaxOffice(nil), offices...), nil
+}
+
+// ClearReferenceCache önbellekteki referans verilerini siler, sonraki çağrılar portaldan yeniden alır
+func (c *Client) ClearReferenceCache() {
+	c.reference.mu.Lock()
+	c.reference.measureUnits = nil
+	c.reference.vatRates = nil
+	c.reference.taxOffices = nil
+	c.reference.mu.Unlock()
+}
+
+// getReferenceData referans veri listesini GET ile alıp v'ye parse eder
+func (c *Client) getReferenceData(path, name string, v interface{}) error {
+	req, err := c.newRequest("GET", c.config.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s listesi isteği başarısız: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package nettefatura
+
+// RoundingMode tutar yuvarlama yöntemi
+type RoundingMode int
+
+const (
+	// RoundHalfUp yarımları sıfırdan uzağa yuvarlar (1.005 -> 1.01)
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven yarımları çift basamağa yuvarlar (banker's rounding)
+	RoundHalfEven
+	// RoundDown sıfıra doğru keser
+	RoundDown
+	// RoundUp sıfırdan uzağa yukarı yuvarlar
+	RoundUp
+)
+
+// RoundAmount tutarı verilen ondalık basamağa seçili yöntemle yuvarlar.
+// Hesaplama ondalık aritmetikle yapılır (bkz. Money).
+func RoundAmount(value float64, decimals int, mode RoundingMode) float64 {
+	return NewMoney(value).Round(decimals, mode).Float64()
+}
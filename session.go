@@ -0,0 +1,269 @@
+package nettefatura
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidCredentials portal girişi reddetti (hatalı VKN/TCKN veya şifre)
+	ErrInvalidCredentials = errors.New("giriş bilgileri hatalı")
+	// ErrSessionExpired oturum kapalı, istek giriş sayfasına yönlendirildi
+	ErrSessionExpired = errors.New("oturum kapalı")
+	// ErrTokenNotFound sayfa 200 döndü ancak CSRF token alanı içermiyor (sayfa yapısı değişmiş olabilir)
+	ErrTokenNotFound = errors.New("token bulunamadı")
+	// ErrCaptchaRequired portal girişte CAPTCHA/bot doğrulaması istiyor. Tekrar denemek
+	// sonuç vermez; girişin tarayıcıdan bir kişi tarafından yapılması gerekir.
+	ErrCaptchaRequired = errors.New("giriş için CAPTCHA doğrulaması gerekli")
+)
+
+var (
+	// captchaMarkerRe bilinen CAPTCHA/bot doğrulama bileşenleri
+	captchaMarkerRe = regexp.MustCompile(`(?i)g-recaptcha|h-captcha|cf-turnstile|cf-challenge|challenge-form|BDC_CaptchaImage|name="(?:CaptchaCode|CaptchaInputText|CaptchaDeText)"`)
+	// captchaIDRe doğrulamanın site anahtarı veya örnek ID'si
+	captchaIDRe = regexp.MustCompile(`(?i)(?:data-sitekey="([^"]+)"|name="(?:BDC_VCID_\w+|CaptchaDeText)"[^>]*\bvalue="([^"]+)")`)
+)
+
+// loginErrorRe giriş formundaki doğrulama özetinin ilk mesajı
+var loginErrorRe = regexp.MustCompile(`(?is)class="[^"]*validation-summary-errors[^"]*"[^>]*>.*?<li>\s*(.*?)\s*</li>`)
+
+// sessionJar Logout'ta eşzamanlı isteklerle yarışmadan boşaltılabilen cookie jar
+type sessionJar struct {
+	mu  sync.RWMutex
+	jar *cookiejar.Jar
+}
+
+// newSessionJar boş cookie jar oluşturur
+func newSessionJar() (*sessionJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("cookie jar oluşturulamadı: %w", err)
+	}
+	return &sessionJar{jar: jar}, nil
+}
+
+// SetCookies http.CookieJar arayüzü
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+// Cookies http.CookieJar arayüzü
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar.Cookies(u)
+}
+
+// reset tüm cookie'leri siler
+func (j *sessionJar) reset() error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("cookie jar oluşturulamadı: %w", err)
+	}
+	j.mu.Lock()
+	j.jar = jar
+	j.mu.Unlock()
+	return nil
+}
+
+// sessionData dışa aktarılan oturum
+type sessionData struct {
+	BaseURL string          `json:"base_url"`
+	Cookies []sessionCookie `json:"cookies"`
+}
+
+// sessionCookie oturum cookie'si
+type sessionCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ExportSession oturum cookie'lerini JSON olarak dışa aktarır.
+// Çıktı parola içermez ancak oturuma erişim sağlar, güvenli saklanmalıdır.
+func (c *Client) ExportSession() ([]byte, error) {
+	baseURL, err := url.Parse(c.config.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("base URL parse hatası: %w", err)
+	}
+
+	data := sessionData{BaseURL: c.config.BaseURL}
+	for _, cookie := range c.httpClient.Jar.Cookies(baseURL) {
+		data.Cookies = append(data.Cookies, sessionCookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	return json.Marshal(data)
+}
+
+// ImportSession ExportSession ile alınan oturumu client'a yükler, Login gerekmez.
+// Oturum farklı bir BaseURL için dışa aktarılmışsa (ör. test ortamı) hata döner.
+func (c *Client) ImportSession(session []byte) error {
+	var data sessionData
+	if err := json.Unmarshal(session, &data); err != nil {
+		return fmt.Errorf("JSON parse hatası: %w", err)
+	}
+
+	if data.BaseURL != "" && !sameBaseURL(data.BaseURL, c.config.BaseURL) {
+		return fmt.Errorf("oturum farklı bir adres için alınmış: %s (client: %s)", data.BaseURL, c.config.BaseURL)
+	}
+
+	cookies := make([]*http.Cookie, 0, len(data.Cookies))
+	for _, cookie := range data.Cookies {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	return c.setCookies(cookies)
+}
+
+// setCookies cookie'leri base URL için jar'a ekler ve token önbelleğini temizler
+func (c *Client) setCookies(cookies []*http.Cookie) error {
+	baseURL, err := url.Parse(c.config.BaseURL)
+	if err != nil {
+		return fmt.Errorf("base URL parse hatası: %w", err)
+	}
+
+	// Cookie'ler tüm yollarda geçerli olmalı; çağıranın cookie'leri değiştirilmez
+	scoped := make([]*http.Cookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		if cookie == nil {
+			continue
+		}
+		copied := *cookie
+		if copied.Path == "" {
+			copied.Path = "/"
+		}
+		scoped = append(scoped, &copied)
+	}
+
+	c.httpClient.Jar.SetCookies(baseURL, scoped)
+	c.invalidateToken()
+	return nil
+}
+
+// sameBaseURL iki base URL'in aynı adresi gösterip göstermediğini sondaki "/" ve
+// büyük/küçük harf farkı gözetmeden kontrol eder
+func sameBaseURL(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
+
+// isLoginForm sayfanın giriş formu olup olmadığını şifre alanından anlar
+func isLoginForm(html string) bool {
+	for _, tag := range inputTagRe.FindAllString(html, -1) {
+		for _, attr := range attributeRe.FindAllStringSubmatch(tag, -1) {
+			if strings.EqualFold(attr[1], "name") && attr[2]+attr[3]+attr[4] == "Password" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loginErrorMessage giriş formundaki hata mesajını döner, yoksa boş
+func loginErrorMessage(html string) string {
+	if matches := loginErrorRe.FindStringSubmatch(html); len(matches) > 1 {
+		return strings.TrimSpace(matches[1])
+	}
+	return ""
+}
+
+// captchaChallenge sayfanın CAPTCHA/bot doğrulaması içerip içermediğini ve varsa
+// doğrulama kimliğini (site anahtarı veya örnek ID'si) döner
+func captchaChallenge(html string) (string, bool) {
+	if !captchaMarkerRe.MatchString(html) {
+		return "", false
+	}
+	if matches := captchaIDRe.FindStringSubmatch(html); matches != nil {
+		return matches[1] + matches[2], true
+	}
+	return "", true
+}
+
+// captchaError doğrulama kimliğini içeren ErrCaptchaRequired hatası oluşturur
+func captchaError(id string) error {
+	if id == "" {
+		return ErrCaptchaRequired
+	}
+	return fmt.Errorf("%w (challenge: %s)", ErrCaptchaRequired, id)
+}
+
+// IsAuthenticated oturumun hâlâ geçerli olup olmadığını yan etkisiz bir GET isteğiyle kontrol eder.
+// Giriş sayfasına yönlendirme veya 200 ile dönen giriş formu oturumun kapandığını gösterir.
+func (c *Client) IsAuthenticated() (bool, error) {
+	// Yönlendirmeyi takip etmeden son yanıtı al
+	noRedirect := *c.httpClient
+	noRedirect.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	req, err := c.newRequest("GET", c.config.BaseURL+"/Invoice/CreateQuick", nil)
+	if err != nil {
+		return false, fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	resp, err := noRedirect.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("oturum kontrol isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		location := strings.ToLower(resp.Header.Get("Location"))
+		return !strings.Contains(location, "/account/login"), nil
+	case resp.StatusCode == http.StatusOK:
+		// Portal bazı durumlarda yönlendirmeden giriş formunu 200 ile döner
+		return !isLoginForm(string(body)), nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("beklenmeyen status: %d", resp.StatusCode)
+	}
+}
+
+// Ping portala erişilebildiğini ve BaseURL'in doğru olduğunu giriş yapmadan kontrol eder.
+// Giriş sayfası 200 ile dönmeli ve CSRF token alanını içermelidir.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := c.newRequestWithContext(ctx, "GET", c.config.BaseURL+"/account/login", nil)
+	if err != nil {
+		return fmt.Errorf("request oluşturulamadı: %w", err)
+	}
+
+	// Oturum cookie'leri gönderilmez, giriş yapılmışsa ana sayfaya yönlendirilmemek için
+	anonymous := *c.httpClient
+	anonymous.Jar = nil
+
+	resp, err := anonymous.Do(req)
+	if err != nil {
+		return fmt.Errorf("ping isteği başarısız: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping başarısız: beklenmeyen status: %d", resp.StatusCode)
+	}
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("response okunamadı: %w", err)
+	}
+
+	if _, ok := extractToken(string(body)); !ok {
+		return fmt.Errorf("ping başarısız: giriş sayfasında token bulunamadı")
+	}
+
+	return nil
+}
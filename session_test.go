@@ -0,0 +1,172 @@
+package nettefatura
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsAuthenticated(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    bool
+	}{
+		{
+			name: "fatura sayfası",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `<form><input name="__RequestVerificationToken" value="abc" /><input name="Aciklama" /></form>`)
+			},
+			want: true,
+		},
+		{
+			name: "200 ile giriş formu",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `<form action="/Account/Login"><input name="VknTckn" /><input type="password" name="Password" /></form>`)
+			},
+			want: false,
+		},
+		{
+			name: "giriş sayfasına yönlendirme",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/account/login?ReturnUrl=%2FInvoice%2FCreateQuick", http.StatusFound)
+			},
+			want: false,
+		},
+		{
+			name: "yetkisiz",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			client, err := NewClient("1", WithBaseURL(srv.URL))
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+
+			got, err := client.IsAuthenticated()
+			if err != nil {
+				t.Fatalf("IsAuthenticated: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsAuthenticated = %v, beklenen %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportSessionBaseURLMismatch(t *testing.T) {
+	source, err := NewClient("1", WithBaseURL("https://test.example.com"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := source.setCookies([]*http.Cookie{{Name: ".ASPXAUTH", Value: "test-session"}}); err != nil {
+		t.Fatalf("setCookies: %v", err)
+	}
+	session, err := source.ExportSession()
+	if err != nil {
+		t.Fatalf("ExportSession: %v", err)
+	}
+
+	other, err := NewClient("1", WithBaseURL("https://prod.example.com"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := other.ImportSession(session); err == nil {
+		t.Error("farklı adresin oturumu kabul edildi")
+	}
+
+	same, err := NewClient("1", WithBaseURL("https://TEST.example.com/"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := same.ImportSession(session); err != nil {
+		t.Errorf("aynı adresin oturumu reddedildi: %v", err)
+	}
+}
+
+func TestSetCookiesDoesNotMutateInput(t *testing.T) {
+	client, err := NewClient("1", WithBaseURL("https://test.example.com"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	cookie := &http.Cookie{Name: ".ASPXAUTH", Value: "test-session"}
+	if err := client.setCookies([]*http.Cookie{cookie}); err != nil {
+		t.Fatalf("setCookies: %v", err)
+	}
+	if cookie.Path != "" {
+		t.Errorf("çağıranın cookie'si değişti: Path = %q", cookie.Path)
+	}
+
+	session, err := client.ExportSession()
+	if err != nil {
+		t.Fatalf("ExportSession: %v", err)
+	}
+	if !strings.Contains(string(session), "test-session") {
+		t.Errorf("cookie jar'a eklenmedi: %s", session)
+	}
+}
+
+func TestSetCompanyIDConcurrent(t *testing.T) {
+	client, err := NewClient("1")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			client.SetCompanyID("2")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if id := client.companyID(); id != "1" && id != "2" {
+			t.Fatalf("firma ID = %q", id)
+		}
+	}
+	<-done
+
+	if err := client.SetCompanyID(""); err == nil {
+		t.Error("boş firma ID kabul edildi")
+	}
+	if id := client.companyID(); id != "2" {
+		t.Errorf("firma ID = %q, beklenen 2", id)
+	}
+}
+
+func TestConfigConcurrentWithSetCompanyID(t *testing.T) {
+	client, err := NewClient("1")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			client.SetCompanyID(fmt.Sprintf("%d", i+2))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if id := client.Config().CompanyID; id == "" {
+			t.Fatal("firma ID boş kopyalandı")
+		}
+	}
+	<-done
+
+	if id := client.Config().CompanyID; id != "101" {
+		t.Errorf("Config().CompanyID = %q, beklenen 101", id)
+	}
+}
@@ -0,0 +1,73 @@
+package nettefatura
+
+import "testing"
+
+func TestSimilarityAlgorithmsTurkishNames(t *testing.T) {
+	tests := []struct {
+		a, b         string
+		levenshtein  float64
+		jaroWinkler  float64
+		sameCustomer bool
+	}{
+		{"Mehmet", "Mehmte", 0.667, 0.967, true},
+		{"Ahmet Yılmaz", "Ahmet Yilmaz", 0.846, 0.967, true},
+		{"Ayşe", "Ayse", 0.600, 0.867, true},
+		{"Kadıköy Mah.", "Kadıköy Mahallesi", 0.684, 0.913, true},
+		{"Atatürk Cad. No:5", "Atatürk Caddesi No 5", 0.762, 0.926, true},
+		{"Mehmet Yılmaz", "Yılmaz Mehmet", 0.143, 0.490, true},
+		{"Ali", "Veli", 0.500, 0.722, false},
+		{"Emre", "Emine", 0.600, 0.827, false},
+		{"Ahmet", "Mehmet", 0.667, 0.697, false},
+	}
+
+	for _, tt := range tests {
+		lev := SimilarityLevenshtein.Score(tt.a, tt.b)
+		jw := SimilarityJaroWinkler.Score(tt.a, tt.b)
+
+		if !approxEqual(lev, tt.levenshtein) {
+			t.Errorf("Levenshtein(%q, %q) = %.3f, beklenen %.3f", tt.a, tt.b, lev, tt.levenshtein)
+		}
+		if !approxEqual(jw, tt.jaroWinkler) {
+			t.Errorf("JaroWinkler(%q, %q) = %.3f, beklenen %.3f", tt.a, tt.b, jw, tt.jaroWinkler)
+		}
+		// Jaro-Winkler her çiftte daha cömerttir
+		if jw < lev {
+			t.Errorf("%q/%q: Jaro-Winkler (%.3f) Levenshtein'dan (%.3f) düşük", tt.a, tt.b, jw, lev)
+		}
+		// Farklı kişilerde Jaro-Winkler erken durma eşiğine (0.8) yaklaşır veya aşar;
+		// bu yüzden varsayılan Levenshtein'dır
+		if !tt.sameCustomer && lev >= 0.8 {
+			t.Errorf("%q/%q: farklı isimler Levenshtein ile yüksek güvenle eşleşti (%.3f)", tt.a, tt.b, lev)
+		}
+	}
+}
+
+func TestSimilarityEdgeCases(t *testing.T) {
+	for _, algorithm := range []SimilarityAlgorithm{SimilarityLevenshtein, SimilarityJaroWinkler} {
+		if got := algorithm.Score("  Ayşe Kaya ", "ayşe kaya"); got != 1 {
+			t.Errorf("%d: aynı isim skoru = %v, beklenen 1", algorithm, got)
+		}
+		if got := algorithm.Score("", "Ayşe"); got != 0 {
+			t.Errorf("%d: boş isim skoru = %v, beklenen 0", algorithm, got)
+		}
+	}
+}
+
+func TestDefaultSimilarityIsLevenshtein(t *testing.T) {
+	client, err := NewClient("1")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if client.config.Similarity != SimilarityLevenshtein {
+		t.Errorf("varsayılan algoritma = %d, beklenen SimilarityLevenshtein", client.config.Similarity)
+	}
+	if defaultMatchScorer.similarity != SimilarityLevenshtein {
+		t.Errorf("paket skorlayıcısı = %d, beklenen SimilarityLevenshtein", defaultMatchScorer.similarity)
+	}
+}
+
+// approxEqual skorları üç basamak hassasiyetle karşılaştırır
+func approxEqual(a, b float64) bool {
+	diff := a - b
+	return diff < 0.0005 && diff > -0.0005
+}
@@ -0,0 +1,70 @@
+package nettefatura
+
+import "fmt"
+
+// SplitInvoice faturanın ürünlerini en fazla maxLines satırlık parçalara böler. Müşteri,
+// tarih, notlar, alıcı ve döviz bilgileri her parçaya kopyalanır; ağ isteği yapılmaz.
+//
+// Toplamlar her parça için CreateInvoice'ta ayrıca hesaplanır ve satırlar parça içinde
+// yuvarlanır, bu yüzden parçaların toplamı tek faturanınkinden kuruş düzeyinde farklı olabilir.
+// Reference verilmişse parçalara sıra eklenir (SIPARIS-1, SIPARIS-2). DiscountRate her
+// parçaya aynen uygulanır; DiscountAmount parçalara KDV hariç tutarları oranında dağıtılır,
+// yuvarlama farkı son parçaya yazılır. maxLines sıfır veya satır sayısı sınırın altındaysa
+// fatura tek parça olarak döner.
+func SplitInvoice(invoice Invoice, maxLines int) []Invoice {
+	if maxLines <= 0 || len(invoice.Products) <= maxLines {
+		return []Invoice{invoice}
+	}
+
+	var parts []Invoice
+	for start := 0; start < len(invoice.Products); start += maxLines {
+		end := start + maxLines
+		if end > len(invoice.Products) {
+			end = len(invoice.Products)
+		}
+
+		part := invoice
+		part.Products = append([]Product(nil), invoice.Products[start:end]...)
+		part.Notes = append([]string(nil), invoice.Notes...)
+		part.DispatchList = append([]DispatchReference(nil), invoice.DispatchList...)
+		if invoice.ExtraFields != nil {
+			part.ExtraFields = make(map[string]interface{}, len(invoice.ExtraFields))
+			for key, value := range invoice.ExtraFields {
+				part.ExtraFields[key] = value
+			}
+		}
+		if invoice.Reference != "" {
+			part.Reference = fmt.Sprintf("%s-%d", invoice.Reference, len(parts)+1)
+		}
+
+		parts = append(parts, part)
+	}
+
+	if invoice.DiscountAmount > 0 {
+		splitDiscountAmount(parts, NewMoney(invoice.DiscountAmount))
+	}
+
+	return parts
+}
+
+// splitDiscountAmount belge iskontosu tutarını parçalara KDV hariç tutarları oranında dağıtır
+func splitDiscountAmount(parts []Invoice, discount Money) {
+	lineTotals := make([]Money, len(parts))
+	var grandTotal Money
+	for i, part := range parts {
+		for _, product := range part.Products {
+			lineTotals[i] = lineTotals[i].Add(product.unitPrice().Mul(NewMoney(product.Quantity)))
+		}
+		grandTotal = grandTotal.Add(lineTotals[i])
+	}
+
+	remaining := discount
+	for i := range parts {
+		share := remaining
+		if i < len(parts)-1 && grandTotal.Sign() != 0 {
+			share = discount.Mul(lineTotals[i]).Div(grandTotal).Round(2, RoundHalfUp)
+		}
+		remaining = remaining.Sub(share)
+		parts[i].DiscountAmount = share.Float64()
+	}
+}
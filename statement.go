@@ -0,0 +1,113 @@
+package nettefatura
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatementEntry müşteri ekstresindeki fatura satırı
+type StatementEntry struct {
+	InvoiceID     int
+	InvoiceNumber string
+	Reference     string // Invoice.Reference (sipariş no)
+	Date          time.Time
+	Status        InvoiceStatus
+	Amount        Money // Ödenecek tutar
+	Balance       Money // Bu satır dahil yürüyen bakiye
+}
+
+// Statement müşterinin kesilmiş faturalarına göre cari ekstresi
+type Statement struct {
+	RecipientID int
+	Entries     []StatementEntry // Tarihe göre eskiden yeniye
+	Balance     Money            // Toplam bakiye
+}
+
+// statementDateLayouts fatura listesindeki tarih biçimleri
+var statementDateLayouts = []string{
+	"02-01-2006",
+	"02.01.2006",
+	"02.01.2006 15:04:05",
+	"2006-01-02",
+	"2006-01-02T15:04:05",
+}
+
+// parseStatementDate fatura listesindeki tarihi parse eder, okunamazsa sıfır zaman döner
+func parseStatementDate(text string) time.Time {
+	text = strings.TrimSpace(text)
+	for _, layout := range statementDateLayouts {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// GetRecipientStatement müşterinin portalda kesilmiş faturalarından cari ekstresini oluşturur.
+// Taslak, reddedilmiş ve iptal edilmiş faturalar bakiyeye dahil edilmez. Portal tahsilat
+// tutmadığından bakiye kesilen faturaların toplamıdır; ödemeler uygulama tarafında düşülmelidir.
+func (c *Client) GetRecipientStatement(recipientID int) (*Statement, error) {
+	if recipientID <= 0 {
+		return nil, fmt.Errorf("müşteri ID gerekli")
+	}
+
+	filters := url.Values{
+		"RecipientIdFilter": {fmt.Sprintf("%d", recipientID)},
+	}
+
+	var entries []StatementEntry
+	start := 0
+	length := 200
+
+	for {
+		result, err := c.getInvoiceList(start, length, "", filters)
+		if err != nil {
+			return nil, fmt.Errorf("müşteri ekstresi alınamadı: %w", err)
+		}
+
+		for _, item := range result.Data {
+			// Filtre uygulanmamış yanıtlarda diğer müşterilerin faturaları atlanır
+			if item.RecipientId != 0 && item.RecipientId != recipientID {
+				continue
+			}
+
+			status := parseInvoiceStatus(item.StatusName)
+			if status == InvoiceStatusDraft || status == InvoiceStatusRejected || status == InvoiceStatusCancelled {
+				continue
+			}
+
+			entries = append(entries, StatementEntry{
+				InvoiceID:     item.InvoiceId,
+				InvoiceNumber: item.InvoiceNumber,
+				Reference:     item.OrderNumber,
+				Date:          parseStatementDate(item.InvoiceDate),
+				Status:        status,
+				Amount:        NewMoney(item.PayableAmount),
+			})
+		}
+
+		if len(result.Data) < length {
+			break
+		}
+		start += length
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	balance := NewMoney(0)
+	for i := range entries {
+		balance = balance.Add(entries[i].Amount)
+		entries[i].Balance = balance
+	}
+
+	return &Statement{
+		RecipientID: recipientID,
+		Entries:     entries,
+		Balance:     balance,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package nettefatura
+
+// anonymousTCKN kimliği bilinmeyen bireysel alıcılar için GİB'in kabul ettiği TCKN
+const anonymousTCKN = "11111111111"
+
+// isValidTCKN 11 haneli TC kimlik numarasının kontrol hanelerini doğrular
+func isValidTCKN(tckn string) bool {
+	if tckn == anonymousTCKN {
+		return true
+	}
+	if !isDigits(tckn, 11) || tckn[0] == '0' {
+		return false
+	}
+
+	d := make([]int, 11)
+	for i := range tckn {
+		d[i] = int(tckn[i] - '0')
+	}
+
+	odd := d[0] + d[2] + d[4] + d[6] + d[8]
+	even := d[1] + d[3] + d[5] + d[7]
+	if ((odd*7-even)%10+10)%10 != d[9] {
+		return false
+	}
+
+	sum := 0
+	for _, digit := range d[:10] {
+		sum += digit
+	}
+	return sum%10 == d[10]
+}
+
+// isValidVKN 10 haneli vergi kimlik numarasının kontrol hanesini doğrular
+func isValidVKN(vkn string) bool {
+	if !isDigits(vkn, 10) {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 9; i++ {
+		digit := int(vkn[i] - '0')
+		v1 := (digit + 9 - i) % 10
+		v2 := (v1 * (1 << (9 - i))) % 9
+		if v1 != 0 && v2 == 0 {
+			v2 = 9
+		}
+		sum += v2
+	}
+
+	return (10-sum%10)%10 == int(vkn[9]-'0')
+}
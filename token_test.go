@@ -0,0 +1,87 @@
+package nettefatura
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		token string
+		ok    bool
+	}{
+		{
+			name:  "name önce",
+			html:  `<input name="__RequestVerificationToken" type="hidden" value="abc123" />`,
+			token: "abc123",
+			ok:    true,
+		},
+		{
+			name:  "value önce",
+			html:  `<input type="hidden" value="abc123" name="__RequestVerificationToken">`,
+			token: "abc123",
+			ok:    true,
+		},
+		{
+			name:  "tek tırnak",
+			html:  `<input name='__RequestVerificationToken' type='hidden' value='abc123'/>`,
+			token: "abc123",
+			ok:    true,
+		},
+		{
+			name:  "çok satırlı",
+			html:  "<form>\n<INPUT\n  type=\"hidden\"\n  name=\"__RequestVerificationToken\"\n  value=\"abc-123_X\"\n/>\n</form>",
+			token: "abc-123_X",
+			ok:    true,
+		},
+		{
+			name:  "tırnaksız ve diğer inputlar",
+			html:  `<input name="Email" value="x@example.com"><input name=__RequestVerificationToken value=abc123>`,
+			token: "abc123",
+			ok:    true,
+		},
+		{
+			name: "boş değer",
+			html: `<input name="__RequestVerificationToken" value="" />`,
+		},
+		{
+			name: "token yok",
+			html: `<form><input name="Email" value="x@example.com" /></form>`,
+		},
+		{
+			name: "sadece metinde geçiyor",
+			html: `<script>var n = "__RequestVerificationToken"; var value = "abc123";</script>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, ok := extractToken(tt.html)
+			if ok != tt.ok || token != tt.token {
+				t.Errorf("extractToken = %q, %v; beklenen %q, %v", token, ok, tt.token, tt.ok)
+			}
+		})
+	}
+}
+
+func TestUpdateTokenNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><form><input name="Aciklama" value="" /></form></body></html>`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient("1", WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	err = client.updateToken("/Invoice/CreateQuick")
+	if !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("hata = %v, beklenen ErrTokenNotFound", err)
+	}
+}
@@ -0,0 +1,145 @@
+package nettefatura
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTotalsClient varsayılan yuvarlama ayarlarıyla ağ bağlantısız client döner
+func newTotalsClient() *Client {
+	return &Client{config: &Config{
+		CurrencyCode:     "TRY",
+		RoundingDecimals: 2,
+		RoundingMode:     RoundHalfUp,
+	}}
+}
+
+func TestBuildProductLinesNegativeLines(t *testing.T) {
+	c := newTotalsClient()
+
+	lines, totals, err := c.buildProductLines(Invoice{Products: []Product{
+		{Name: "Hizmet", Quantity: 2, Price: 100, VATRate: 20},
+		{Name: "İndirim", Quantity: 1, Price: -50, VATRate: 20},
+		{Name: "İade", Quantity: -1, Price: 30, VATRate: 10},
+	}})
+	if err != nil {
+		t.Fatalf("buildProductLines: %v", err)
+	}
+
+	wantLines := []struct {
+		lineExtension, vat float64
+	}{
+		{200, 40},
+		{-50, -10},
+		{-30, -3},
+	}
+	for i, want := range wantLines {
+		if got := lines[i]["LineExtensionAmount"]; got != want.lineExtension {
+			t.Errorf("%d. satır tutarı = %v, beklenen %v", i+1, got, want.lineExtension)
+		}
+		// Negatif satırın KDV'si de negatiftir
+		if got := lines[i]["VatAmount"]; got != want.vat {
+			t.Errorf("%d. satır KDV = %v, beklenen %v", i+1, got, want.vat)
+		}
+	}
+
+	if totals.LineExtension.Float64() != 120 || totals.VAT.Float64() != 27 || totals.Total.Float64() != 147 {
+		t.Errorf("toplamlar = %s / %s / %s, beklenen 120 / 27 / 147", totals.LineExtension, totals.VAT, totals.Total)
+	}
+	if totals.Payable.Float64() != 147 {
+		t.Errorf("ödenecek = %s, beklenen 147", totals.Payable)
+	}
+}
+
+func TestBuildProductLinesNegativeTotal(t *testing.T) {
+	c := newTotalsClient()
+
+	_, _, err := c.buildProductLines(Invoice{Products: []Product{
+		{Name: "Hizmet", Quantity: 1, Price: 10, VATRate: 20},
+		{Name: "İndirim", Quantity: 1, Price: -20, VATRate: 20},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "negatif olamaz") {
+		t.Errorf("hata = %v, beklenen negatif toplam hatası", err)
+	}
+}
+
+func TestBuildProductLinesZeroTotal(t *testing.T) {
+	c := newTotalsClient()
+
+	_, totals, err := c.buildProductLines(Invoice{Products: []Product{
+		{Name: "Hizmet", Quantity: 1, Price: 100, VATRate: 20},
+		{Name: "Tam indirim", Quantity: -1, Price: 100, VATRate: 20},
+	}})
+	if err != nil {
+		t.Fatalf("sıfır toplam reddedildi: %v", err)
+	}
+	if !totals.Total.IsZero() || !totals.VAT.IsZero() || !totals.Payable.IsZero() {
+		t.Errorf("toplamlar = %s / %s / %s, beklenen sıfır", totals.Total, totals.VAT, totals.Payable)
+	}
+}
+
+func TestValidateSignedLines(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		product Product
+		wantErr string
+	}{
+		{"negatif fiyat", Product{Name: "İndirim", Quantity: 1, Price: -10, VATRate: 20}, ""},
+		{"negatif miktar", Product{Name: "İade", Quantity: -2, Price: 10, VATRate: 20}, ""},
+		{"ikisi birden negatif", Product{Name: "Hatalı", Quantity: -1, Price: -10, VATRate: 20}, "birlikte negatif olamaz"},
+		{"sıfır miktar", Product{Name: "Boş", Quantity: 0, Price: 10, VATRate: 20}, "miktar sıfır olamaz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := Invoice{
+				CustomerID: "1001",
+				Products:   []Product{{Name: "Hizmet", Quantity: 1, Price: 100, VATRate: 20}, tt.product},
+			}
+			err := invoice.validate(DefaultVATRates(), now)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("beklenmeyen hata: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("hata = %v, beklenen %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildProductLinesRoundAdjustment(t *testing.T) {
+	// Satır yuvarlaması ham toplamdan 0.015 kayar (3 x 1.005 -> 3.03)
+	products := []Product{
+		{Name: "A", Quantity: 1, Price: 1.005, VATRate: 0},
+		{Name: "B", Quantity: 1, Price: 1.005, VATRate: 0},
+		{Name: "C", Quantity: 1, Price: 1.005, VATRate: 0},
+	}
+
+	c := newTotalsClient()
+	_, totals, err := c.buildProductLines(Invoice{Products: products})
+	if err != nil {
+		t.Fatalf("buildProductLines: %v", err)
+	}
+	if !totals.RoundAdjustment.IsZero() {
+		t.Errorf("RoundAdjustment = %s, WithRoundTotalTo olmadan sıfır olmalı", totals.RoundAdjustment)
+	}
+	if totals.Payable.Cmp(totals.Total) != 0 || totals.Total.Float64() != 3.03 {
+		t.Errorf("toplam / ödenecek = %s / %s, beklenen 3.03 / 3.03", totals.Total, totals.Payable)
+	}
+
+	// Yuvarlama satırı ödenecek tutarı yuvarlanmış değere tamamlar
+	c.config.RoundTotalTo = 1
+	_, totals, err = c.buildProductLines(Invoice{Products: products})
+	if err != nil {
+		t.Fatalf("buildProductLines: %v", err)
+	}
+	if totals.Payable.Float64() != 3 || totals.RoundAdjustment.Float64() != -0.03 {
+		t.Errorf("ödenecek / yuvarlama = %s / %s, beklenen 3 / -0.03", totals.Payable, totals.RoundAdjustment)
+	}
+	if got := totals.Total.Sub(totals.Discount).Add(totals.RoundAdjustment); got.Cmp(totals.Payable) != 0 {
+		t.Errorf("toplam + yuvarlama = %s, ödenecek %s", got, totals.Payable)
+	}
+}
@@ -0,0 +1,114 @@
+package nettefatura
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ErrResponseTooLarge yanıt gövdesi MaxResponseBytes sınırını aştığında döner
+var ErrResponseTooLarge = errors.New("yanıt boyutu sınırı aşıldı")
+
+// newBaseTransport bağlantı ve yanıt başlığı zaman aşımlarını uygulayan transport oluşturur.
+// Zaman aşımı verilmemişse http.DefaultTransport kullanılır.
+func newBaseTransport(config *Config) http.RoundTripper {
+	if config.DialTimeout <= 0 && config.ResponseHeaderTimeout <= 0 {
+		return http.DefaultTransport
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.DialTimeout > 0 {
+		dialer := &net.Dialer{Timeout: config.DialTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = config.DialTimeout
+	}
+	if config.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+	}
+	return transport
+}
+
+// newRequest client'ın context'iyle istek oluşturur
+func (c *Client) newRequest(method, url string, body io.Reader) (*http.Request, error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return c.newRequestWithContext(ctx, method, url, body)
+}
+
+// newRequestWithContext WithHeader ile tanımlanan header'ları içeren istek oluşturur
+func (c *Client) newRequestWithContext(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.config.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return req, nil
+}
+
+// headerTransport tüm giden isteklere ortak header'ları ekler
+type headerTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip isteğin kopyasına header'ları ekleyip gönderir
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// readBody yanıt gövdesini MaxResponseBytes sınırına kadar okur.
+// Sınır aşılırsa gövdenin tamamı okunmadan ErrResponseTooLarge döner.
+func (c *Client) readBody(r io.Reader) ([]byte, error) {
+	return io.ReadAll(c.limitBody(r))
+}
+
+// limitBody okumayı MaxResponseBytes ile sınırlayan reader döner. Akış halinde
+// parse edilen yanıtlar (json.Decoder) da bu reader üzerinden okunmalıdır.
+func (c *Client) limitBody(r io.Reader) io.Reader {
+	if c.config.MaxResponseBytes <= 0 {
+		return r
+	}
+	return &cappedReader{r: r, remaining: c.config.MaxResponseBytes, limit: c.config.MaxResponseBytes}
+}
+
+// cappedReader sınırdan sonra veri gelirse ErrResponseTooLarge döner
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+	limit     int64
+}
+
+// Read sınıra kadar okur, sınır aşıldıysa hata döner
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		// Sınırda tam bitip bitmediğini anlamak için bir bayt daha denenir
+		var probe [1]byte
+		n, err := c.r.Read(probe[:])
+		if n > 0 {
+			return 0, fmt.Errorf("%w: en fazla %d bayt", ErrResponseTooLarge, c.limit)
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	return n, err
+}
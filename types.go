@@ -0,0 +1,44 @@
+package nettefatura
+
+// CustomerType portaldaki alıcı tipi (AliciTipi)
+type CustomerType int
+
+const (
+	CustomerTypeAuto       CustomerType = 0 // VKN/TCKN uzunluğuna göre belirlenir
+	CustomerTypeIndividual CustomerType = 1 // Bireysel (TCKN)
+	CustomerTypeCorporate  CustomerType = 2 // Kurumsal (VKN, şahıs şirketinde TCKN)
+)
+
+// SendingType fatura gönderim şekli (FaturaGonderimSekli)
+type SendingType int
+
+const (
+	SendingTypeDefault    SendingType = 0 // Müşteri kaydındaki şekil, yoksa elektronik
+	SendingTypeElectronic SendingType = 1 // Elektronik (e-posta zorunlu)
+	SendingTypePaper      SendingType = 2 // Kağıt
+)
+
+// RecipientState müşteri kaydının durumu (RecipientState filtresi)
+type RecipientState int
+
+const (
+	RecipientStateDefault RecipientState = 0 // Filtrede aktif müşteriler
+	RecipientStateActive  RecipientState = 1 // Aktif
+	RecipientStatePassive RecipientState = 2 // Pasif
+)
+
+// RecipientType faturanın alıcı tipi; portala string olarak gönderilir
+type RecipientType string
+
+const (
+	RecipientTypeAuto     RecipientType = ""  // Mükellef sorgusuyla belirlenir
+	RecipientTypeEInvoice RecipientType = "1" // e-Fatura
+	RecipientTypeEArchive RecipientType = "2" // e-Arşiv
+)
+
+// InvoiceType fatura tipi; portala string olarak gönderilir
+type InvoiceType string
+
+const (
+	InvoiceTypeSales InvoiceType = "1" // Satış faturası
+)
@@ -0,0 +1,14 @@
+package nettefatura
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Version paket sürümü
+const Version = "0.1.0"
+
+// UserAgent varsayılan User-Agent'ı döner (ör. "nettefatura-go/0.1.0 (go1.21.0; linux/amd64)")
+func UserAgent() string {
+	return fmt.Sprintf("nettefatura-go/%s (%s; %s/%s)", Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}